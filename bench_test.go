@@ -0,0 +1,103 @@
+package hashmaps_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/EinfachAndy/hashmaps"
+	"github.com/EinfachAndy/hashmaps/shared"
+	"github.com/EinfachAndy/hashmaps/unordered"
+)
+
+var (
+	benchTypes = map[string]hashmaps.Type{
+		"hopscotch": hashmaps.Hopscotch,
+		"robin":     hashmaps.Robin,
+		"flat":      hashmaps.Flat,
+	}
+	benchLayouts = map[string]shared.Layout{
+		"aos": shared.AoS,
+		"soa": shared.SoA,
+	}
+)
+
+func benchLayout[V any](b *testing.B, newValue func(i uint64) V) {
+	const n = 1 << 16
+
+	for tName, typ := range benchTypes {
+		for lName, layout := range benchLayouts {
+			m := hashmaps.MustNewHashMap(hashmaps.Config[uint64, V]{
+				Type:    typ,
+				MaxLoad: 0.8,
+				Layout:  layout,
+			})
+
+			for i := uint64(1); i <= n; i++ {
+				m.Put(i, newValue(i))
+			}
+
+			b.Run(fmt.Sprintf("%s/%s/hit", tName, lName), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					m.Get(uint64(i%n) + 1)
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/%s/miss", tName, lName), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					m.Get(uint64(i%n) + n + 1)
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/%s/put", tName, lName), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					m.Put(uint64(i%n)+1, newValue(uint64(i)))
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkLayoutValue8(b *testing.B) {
+	benchLayout(b, func(i uint64) uint64 { return i })
+}
+
+func BenchmarkLayoutValue128(b *testing.B) {
+	benchLayout(b, func(i uint64) [16]uint64 { return [16]uint64{i} })
+}
+
+func BenchmarkUnorderedInline(b *testing.B) {
+	const n = 1 << 16
+
+	var (
+		stable = unordered.New[uint64, uint64]()
+		inline = unordered.NewInline[uint64, uint64]()
+		maps   = map[string]hashmaps.Map[uint64, uint64]{
+			"stable": stable,
+			"inline": inline,
+		}
+	)
+
+	for name, m := range maps {
+		for i := uint64(1); i <= n; i++ {
+			m.Put(i, i)
+		}
+
+		b.Run(name+"/hit", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Get(uint64(i%n) + 1)
+			}
+		})
+
+		b.Run(name+"/miss", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Get(uint64(i%n) + n + 1)
+			}
+		})
+
+		b.Run(name+"/put", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Put(uint64(i%(2*n))+1, uint64(i))
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package hashmaps
+
+import (
+	"unsafe"
+)
+
+// The functions of this file look up string keys, which are given as bytes,
+// without allocating a string. The bytes are viewed as string, which is not
+// retained by the hashmaps, so the hash value matches the hasher of the
+// hashmap. The bytes must not be modified during the call.
+
+// GetBytes returns the value stored for the key or false if not found.
+func GetBytes[K ~string, V any](m interface{ Get(key K) (V, bool) }, key []byte) (V, bool) {
+	return m.Get(bytesKey[K](key))
+}
+
+// ContainsBytes returns true, if the key is in the hashmap.
+func ContainsBytes[K ~string, V any](m interface{ Get(key K) (V, bool) }, key []byte) bool {
+	_, found := m.Get(bytesKey[K](key))
+	return found
+}
+
+// RemoveBytes removes the key-value pair from the hashmap.
+// Returns true, if the key was in the hashmap.
+func RemoveBytes[K ~string](m interface{ Remove(key K) bool }, key []byte) bool {
+	return m.Remove(bytesKey[K](key))
+}
+
+func bytesKey[K ~string](b []byte) K {
+	return K(unsafe.String(unsafe.SliceData(b), len(b)))
+}
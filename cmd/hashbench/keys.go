@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// structKey is the composite key type of the benchmarks.
+type structKey struct {
+	id  uint64
+	tag uint32
+}
+
+// mix is the finalizer of splitmix64. It is a bijection, which maps only
+// zero to zero, so the keys are unique and never the empty key of `Flat`.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
+
+// intKeys returns 2n unique keys, the first n are inserted
+// and the others are used for unsuccessful lookups.
+func intKeys(n int) []uint64 {
+	keys := make([]uint64, 2*n)
+	for i := range keys {
+		keys[i] = mix(uint64(i) + 1)
+	}
+
+	return keys
+}
+
+// stringKeys same as `intKeys` but for strings of 16 characters.
+func stringKeys(n int) []string {
+	keys := make([]string, 2*n)
+	for i := range keys {
+		keys[i] = strconv.FormatUint(mix(uint64(i)+1)|1<<63, 16)
+	}
+
+	return keys
+}
+
+// structKeys same as `intKeys` but for the `structKey` type.
+func structKeys(n int) []structKey {
+	keys := make([]structKey, 2*n)
+	for i := range keys {
+		keys[i] = structKey{id: mix(uint64(i) + 1), tag: uint32(i)}
+	}
+
+	return keys
+}
+
+func structHasher() shared.HashFn[structKey] {
+	hasher := shared.GetHasher[uint64]()
+
+	return func(k structKey) uintptr {
+		return hasher(k.id ^ uint64(k.tag)<<32)
+	}
+}
+
+// builtin adapts the golang map to the `hashmaps.Map` interface.
+type builtin[K comparable, V any] map[K]V
+
+func (m builtin[K, V]) Get(key K) (V, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m builtin[K, V]) Put(key K, val V) bool {
+	_, ok := m[key]
+	m[key] = val
+
+	return !ok
+}
+
+func (m builtin[K, V]) Remove(key K) bool {
+	_, ok := m[key]
+	delete(m, key)
+
+	return ok
+}
+
+func (m builtin[K, V]) Reserve(uintptr)          {}
+func (m builtin[K, V]) Load() float32            { return 0 }
+func (m builtin[K, V]) Clear()                   { clear(m) }
+func (m builtin[K, V]) Size() int                { return len(m) }
+func (m builtin[K, V]) MaxLoad(lf float32) error { return nil }
+func (m builtin[K, V]) Each(fn func(K, V) bool) {
+	for k, v := range m {
+		if fn(k, v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,342 @@
+// Command hashbench compares the hashmaps of this module and the golang map
+// by configurable workloads. It reports the runtime and the allocations per
+// operation and the peak heap memory of each hashmap as table or CSV.
+//
+// Usage:
+//
+//	go run ./cmd/hashbench -keys int,string -values 8,64 -loads 0.5,0.9 -format csv
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/EinfachAndy/hashmaps"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// builtinName selects the golang map in the types flag.
+const builtinName = "builtin"
+
+var allTypes = []hashmaps.Type{
+	hashmaps.Hopscotch,
+	hashmaps.Robin,
+	hashmaps.Unordered,
+	hashmaps.Flat,
+	hashmaps.IndexMap,
+}
+
+// config holds the parsed command line flags.
+type config struct {
+	n         int
+	ops       int
+	seed      int64
+	types     []string
+	keys      []string
+	values    []int
+	loads     []float32
+	workloads []string
+	ratio     [3]int
+	format    string
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "hashbench:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	var results []result
+
+	for _, key := range cfg.keys {
+		var res []result
+
+		switch key {
+		case "int":
+			res, err = benchValues(cfg, key, intKeys(cfg.n), shared.GetHasher[uint64]())
+		case "string":
+			res, err = benchValues(cfg, key, stringKeys(cfg.n), shared.GetHasher[string]())
+		case "struct":
+			res, err = benchValues(cfg, key, structKeys(cfg.n), structHasher())
+		default:
+			err = fmt.Errorf("key type %q: %w", key, shared.ErrUnsupported)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		results = append(results, res...)
+	}
+
+	if cfg.format == "csv" {
+		return writeCSV(out, results)
+	}
+
+	return writeTable(out, results)
+}
+
+func parseFlags(args []string) (config, error) {
+	var (
+		cfg       config
+		fs        = flag.NewFlagSet("hashbench", flag.ContinueOnError)
+		types     = fs.String("types", "hopscotch,robin,unordered,flat,indexmap,"+builtinName, "hashmap types")
+		keys      = fs.String("keys", "int,string,struct", "key types: int, string or struct")
+		values    = fs.String("values", "8,64", "value sizes in bytes: 8, 16, 32, 64, 128 or 256")
+		loads     = fs.String("loads", "0.5,0.7,0.9", "max load factors")
+		workloads = fs.String("workloads", "insert,hit,miss,delete,mixed", "workloads")
+		ratio     = fs.String("mix", "80:15:5", "get:put:remove ratio of the mixed workload")
+	)
+
+	fs.IntVar(&cfg.n, "n", 100000, "number of inserted keys")
+	fs.IntVar(&cfg.ops, "ops", 1000000, "number of operations of the hit, miss and mixed workloads")
+	fs.Int64Var(&cfg.seed, "seed", 1, "seed of the random operations")
+	fs.StringVar(&cfg.format, "format", "table", "output format: table or csv")
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if cfg.n <= 0 || cfg.ops <= 0 {
+		return cfg, fmt.Errorf("n %d, ops %d: %w", cfg.n, cfg.ops, shared.ErrOutOfRange)
+	}
+
+	if cfg.format != "table" && cfg.format != "csv" {
+		return cfg, fmt.Errorf("format %q: %w", cfg.format, shared.ErrUnsupported)
+	}
+
+	cfg.types = split(*types)
+	cfg.keys = split(*keys)
+	cfg.workloads = split(*workloads)
+
+	for _, t := range cfg.types {
+		if _, ok := parseType(t); !ok && t != builtinName {
+			return cfg, fmt.Errorf("type %q: %w", t, shared.ErrUnsupported)
+		}
+	}
+
+	for _, v := range split(*values) {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+
+		cfg.values = append(cfg.values, size)
+	}
+
+	for _, l := range split(*loads) {
+		lf, err := strconv.ParseFloat(l, 32)
+		if err != nil {
+			return cfg, err
+		}
+
+		if lf <= 0.0 {
+			return cfg, fmt.Errorf("load %f: %w", lf, shared.ErrOutOfRange)
+		}
+
+		cfg.loads = append(cfg.loads, float32(lf))
+	}
+
+	// the upper bound of the load factor depends on the type
+	for _, t := range cfg.types {
+		typ, ok := parseType(t)
+		if !ok {
+			continue
+		}
+
+		for _, lf := range cfg.loads {
+			if _, err := hashmaps.NewHashMap(hashmaps.Config[uint64, uint64]{Type: typ, MaxLoad: lf}); err != nil {
+				return cfg, fmt.Errorf("type %s: %w", t, err)
+			}
+		}
+	}
+
+	parts := strings.Split(*ratio, ":")
+	if len(parts) != len(cfg.ratio) {
+		return cfg, fmt.Errorf("mix %q: %w", *ratio, shared.ErrOutOfRange)
+	}
+
+	for i, p := range parts {
+		r, err := strconv.Atoi(p)
+		if err != nil || r < 0 {
+			return cfg, fmt.Errorf("mix %q: %w", *ratio, shared.ErrOutOfRange)
+		}
+
+		cfg.ratio[i] = r
+	}
+
+	if cfg.ratio[0]+cfg.ratio[1]+cfg.ratio[2] == 0 {
+		return cfg, fmt.Errorf("mix %q: %w", *ratio, shared.ErrOutOfRange)
+	}
+
+	return cfg, nil
+}
+
+func split(s string) []string {
+	var res []string
+
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			res = append(res, part)
+		}
+	}
+
+	return res
+}
+
+func parseType(name string) (hashmaps.Type, bool) {
+	for _, t := range allTypes {
+		if strings.EqualFold(t.String(), name) {
+			return t, true
+		}
+	}
+
+	return 0, false
+}
+
+// benchValues runs the benchmarks for all value sizes.
+func benchValues[K comparable](cfg config, keyName string, keys []K, hasher shared.HashFn[K]) ([]result, error) {
+	var (
+		res []result
+		err error
+	)
+
+	for _, size := range cfg.values {
+		var r []result
+
+		switch size {
+		case 8:
+			r, err = bench[K, [1]uint64](cfg, keyName, size, keys, hasher)
+		case 16:
+			r, err = bench[K, [2]uint64](cfg, keyName, size, keys, hasher)
+		case 32:
+			r, err = bench[K, [4]uint64](cfg, keyName, size, keys, hasher)
+		case 64:
+			r, err = bench[K, [8]uint64](cfg, keyName, size, keys, hasher)
+		case 128:
+			r, err = bench[K, [16]uint64](cfg, keyName, size, keys, hasher)
+		case 256:
+			r, err = bench[K, [32]uint64](cfg, keyName, size, keys, hasher)
+		default:
+			err = fmt.Errorf("value size %d: %w", size, shared.ErrUnsupported)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, r...)
+	}
+
+	return res, nil
+}
+
+// bench runs the workloads for all types and load factors.
+// The golang map is measured once, because the load factor is fixed.
+func bench[K comparable, V any](cfg config, keyName string, size int, keys []K, hasher shared.HashFn[K]) ([]result, error) {
+	var (
+		res = []result{}
+		all = workloads[K, V](keys, cfg.ops, cfg.ratio, cfg.seed)
+	)
+
+	for _, name := range cfg.workloads {
+		w, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("workload %q: %w", name, shared.ErrUnsupported)
+		}
+
+		for _, typeName := range cfg.types {
+			if typeName == builtinName {
+				r := result{typ: builtinName, key: keyName, value: size, load: "-", workload: name}
+				measure(func() hashmaps.Map[K, V] { return builtin[K, V]{} }, keys, w, &r)
+				res = append(res, r)
+
+				continue
+			}
+
+			typ, _ := parseType(typeName)
+
+			for _, lf := range cfg.loads {
+				opts := []hashmaps.Option{hashmaps.WithHasher(hasher), hashmaps.WithMaxLoad(lf)}
+
+				// validate the options once
+				if _, err := hashmaps.NewHashMap(hashmaps.Config[K, V]{Type: typ}, opts...); err != nil {
+					return nil, err
+				}
+
+				r := result{
+					typ:      strings.ToLower(typ.String()),
+					key:      keyName,
+					value:    size,
+					load:     strconv.FormatFloat(float64(lf), 'f', 2, 32),
+					workload: name,
+				}
+				measure(func() hashmaps.Map[K, V] {
+					return hashmaps.MustNewHashMap(hashmaps.Config[K, V]{Type: typ}, opts...)
+				}, keys, w, &r)
+				res = append(res, r)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+var header = []string{"type", "key", "value", "load", "workload", "ns/op", "allocs/op", "B/op", "peak"}
+
+func (r result) columns() []string {
+	return []string{
+		r.typ,
+		r.key,
+		strconv.Itoa(r.value),
+		r.load,
+		r.workload,
+		strconv.FormatFloat(r.nsPerOp, 'f', 2, 64),
+		strconv.FormatFloat(r.allocsPerOp, 'f', 3, 64),
+		strconv.FormatFloat(r.bytesPerOp, 'f', 1, 64),
+		strconv.FormatUint(r.peak, 10),
+	}
+}
+
+func writeTable(out io.Writer, results []result) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, strings.Join(header, "\t")+"\t")
+
+	for _, r := range results {
+		fmt.Fprintln(w, strings.Join(r.columns(), "\t")+"\t")
+	}
+
+	return w.Flush()
+}
+
+func writeCSV(out io.Writer, results []result) error {
+	w := csv.NewWriter(out)
+
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if err := w.Write(r.columns()); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+
+	err := run([]string{
+		"-n", "100", "-ops", "200",
+		"-keys", "int,string,struct", "-values", "8,128", "-loads", "0.5,0.9",
+		"-format", "csv",
+	}, &out)
+	assert.NoError(t, err)
+
+	records, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	// 3 keys * 2 values * 5 workloads * (5 types * 2 loads + builtin)
+	assert.Len(t, records, 1+3*2*5*(5*2+1))
+	assert.Equal(t, header, records[0])
+
+	out.Reset()
+	assert.NoError(t, run([]string{"-n", "10", "-ops", "10", "-keys", "int", "-values", "8", "-types", "flat"}, &out))
+	assert.Contains(t, out.String(), "flat")
+
+	// the chained hashmaps support load factors above 1
+	out.Reset()
+	assert.NoError(t, run([]string{"-n", "10", "-ops", "10", "-keys", "int", "-values", "8", "-types", "unordered", "-loads", "1.5"}, &out))
+	assert.Contains(t, out.String(), "1.50")
+
+	for _, args := range [][]string{
+		{"-keys", "float"},
+		{"-values", "7"},
+		{"-loads", "1.0"},
+		{"-loads", "0"},
+		{"-types", "unordered,robin", "-loads", "1.5"},
+		{"-types", "btree"},
+		{"-workloads", "scan"},
+		{"-mix", "1:2"},
+		{"-format", "json"},
+		{"-n", "0"},
+	} {
+		assert.Error(t, run(append([]string{"-n", "10", "-ops", "10"}, args...), &out), args)
+	}
+}
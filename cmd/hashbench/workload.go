@@ -0,0 +1,188 @@
+package main
+
+import (
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/EinfachAndy/hashmaps"
+)
+
+// samples is the number of heap samples per workload to find the peak memory.
+const samples = 64
+
+// step executes the operations [lo, hi) of a workload.
+type step[K comparable, V any] func(m hashmaps.Map[K, V], lo, hi int)
+
+// workload describes the operations of one benchmark.
+type workload[K comparable, V any] struct {
+	// prefill inserts the first n keys before the measurement
+	prefill bool
+	ops     int
+	step    step[K, V]
+}
+
+// result is one row of the report.
+type result struct {
+	typ      string
+	key      string
+	value    int
+	load     string
+	workload string
+
+	nsPerOp     float64
+	allocsPerOp float64
+	bytesPerOp  float64
+	// peak is the maximum of the heap, which is used by the hashmap
+	peak uint64
+}
+
+// workloads returns the workloads by name. The key slice holds n keys,
+// which are inserted, followed by n keys, which are never inserted.
+// The ratio of mixed is get:put:remove.
+func workloads[K comparable, V any](keys []K, ops int, ratio [3]int, seed int64) map[string]workload[K, V] {
+	var (
+		n      = len(keys) / 2
+		rng    = rand.New(rand.NewSource(seed))
+		val    V
+		hits   = make([]int32, ops)
+		kind   = make([]uint8, ops)
+		keyIdx = make([]int32, ops)
+	)
+
+	for i := 0; i < ops; i++ {
+		hits[i] = int32(rng.Intn(n))
+		keyIdx[i] = int32(rng.Intn(2 * n))
+
+		switch r := rng.Intn(ratio[0] + ratio[1] + ratio[2]); {
+		case r < ratio[0]:
+			kind[i] = 0
+		case r < ratio[0]+ratio[1]:
+			kind[i] = 1
+		default:
+			kind[i] = 2
+		}
+	}
+
+	order := rng.Perm(n)
+
+	return map[string]workload[K, V]{
+		"insert": {
+			ops: n,
+			step: func(m hashmaps.Map[K, V], lo, hi int) {
+				for i := lo; i < hi; i++ {
+					m.Put(keys[i], val)
+				}
+			},
+		},
+		"hit": {
+			prefill: true,
+			ops:     ops,
+			step: func(m hashmaps.Map[K, V], lo, hi int) {
+				for i := lo; i < hi; i++ {
+					m.Get(keys[hits[i]])
+				}
+			},
+		},
+		"miss": {
+			prefill: true,
+			ops:     ops,
+			step: func(m hashmaps.Map[K, V], lo, hi int) {
+				for i := lo; i < hi; i++ {
+					m.Get(keys[n+int(hits[i])])
+				}
+			},
+		},
+		"delete": {
+			prefill: true,
+			ops:     n,
+			step: func(m hashmaps.Map[K, V], lo, hi int) {
+				for i := lo; i < hi; i++ {
+					m.Remove(keys[order[i]])
+				}
+			},
+		},
+		"mixed": {
+			prefill: true,
+			ops:     ops,
+			step: func(m hashmaps.Map[K, V], lo, hi int) {
+				for i := lo; i < hi; i++ {
+					switch key := keys[keyIdx[i]]; kind[i] {
+					case 0:
+						m.Get(key)
+					case 1:
+						m.Put(key, val)
+					default:
+						m.Remove(key)
+					}
+				}
+			},
+		},
+	}
+}
+
+// measure runs the workload on a new hashmap and fills the measured values of 'res'.
+// The timing and the memory sampling are done in separate runs, because the
+// sampling stops the world.
+func measure[K comparable, V any](newMap func() hashmaps.Map[K, V], keys []K, w workload[K, V], res *result) {
+	var (
+		n      = len(keys) / 2
+		insert = func(m hashmaps.Map[K, V], lo, hi int) {
+			var val V
+			for i := lo; i < hi; i++ {
+				m.Put(keys[i], val)
+			}
+		}
+		before runtime.MemStats
+		after  runtime.MemStats
+	)
+
+	// timing
+	m := newMap()
+	if w.prefill {
+		insert(m, 0, n)
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	w.step(m, 0, w.ops)
+	elapsed := time.Since(start)
+
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(m)
+
+	res.nsPerOp = float64(elapsed.Nanoseconds()) / float64(w.ops)
+	res.allocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(w.ops)
+	res.bytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(w.ops)
+
+	// peak memory including the prefill
+	m = nil
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	var (
+		peak   = before.HeapAlloc
+		sample = func(m hashmaps.Map[K, V], fn step[K, V], ops int) {
+			chunk := max(ops/samples, 1)
+
+			for lo := 0; lo < ops; lo += chunk {
+				fn(m, lo, min(lo+chunk, ops))
+				runtime.ReadMemStats(&after)
+				peak = max(peak, after.HeapAlloc)
+			}
+		}
+	)
+
+	m = newMap()
+	if w.prefill {
+		sample(m, insert, n)
+	}
+
+	sample(m, w.step, w.ops)
+	runtime.KeepAlive(m)
+
+	res.peak = peak - before.HeapAlloc
+}
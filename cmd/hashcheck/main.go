@@ -0,0 +1,56 @@
+// Command hashcheck evaluates the quality of the hash functions, that are
+// registered in the package `hashcheck`, see there for the checks.
+// The command exits with status 1, if a check fails.
+//
+// Usage:
+//
+//	go run ./cmd/hashcheck -hashers string,uint64 -n 100000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/EinfachAndy/hashmaps/hashcheck"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "hashcheck:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	var (
+		cfg     hashcheck.Config
+		fs      = flag.NewFlagSet("hashcheck", flag.ContinueOnError)
+		hashers = fs.String("hashers", "", "comma separated names of the checked hashers, all if empty")
+	)
+
+	fs.IntVar(&cfg.N, "n", hashcheck.DefaultN, "maximum number of keys per corpus")
+	fs.IntVar(&cfg.Samples, "samples", hashcheck.DefaultSamples, "number of random keys of the avalanche checks")
+	fs.IntVar(&cfg.Bits, "bits", hashcheck.DefaultBits, "number of lower output bits of the avalanche checks")
+	fs.Int64Var(&cfg.Seed, "seed", 1, "seed of the random keys")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// zero selects the defaults of the package, but not on the command line
+	if cfg.N <= 0 || cfg.Samples <= 0 || cfg.Bits <= 0 {
+		return fmt.Errorf("n %d, samples %d, bits %d: %w", cfg.N, cfg.Samples, cfg.Bits, shared.ErrOutOfRange)
+	}
+
+	for _, name := range strings.Split(*hashers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Hashers = append(cfg.Hashers, name)
+		}
+	}
+
+	return hashcheck.Run(out, cfg)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+
+	// the murmur finalizer passes all checks
+	assert.NoError(t, run([]string{"-hashers", "uint64,float64,wyhash/string", "-n", "5000", "-samples", "2000"}, &out))
+	assert.NotContains(t, out.String(), "FAIL")
+
+	// the string hasher folds the tail bytes and collides on short strings
+	out.Reset()
+	assert.Error(t, run([]string{"-hashers", "string", "-n", "5000", "-samples", "2000"}, &out))
+	assert.Regexp(t, `string\s+collisions\s+short\s+\S+\s+\S+\s+FAIL`, out.String())
+
+	for _, args := range [][]string{
+		{"-hashers", "complex64"},
+		{"-n", "0"},
+		{"-bits", "65"},
+		{"-samples", "0"},
+	} {
+		assert.Error(t, run(args, &out), args)
+	}
+}
@@ -0,0 +1,158 @@
+package hashmaps
+
+import (
+	"iter"
+)
+
+// Reader is the read only part of the method set, which all hashmaps
+// of this module implement, see `Map`.
+type Reader[K any, V any] interface {
+	Size() int
+	Each(fn func(key K, val V) bool)
+}
+
+// Writer is the writing part of the method set, which all hashmaps
+// of this module implement, see `Map`.
+type Writer[K any, V any] interface {
+	Put(key K, val V) bool
+	Reserve(n uintptr)
+}
+
+// ReadWriter combines `Reader` and `Writer`.
+type ReadWriter[K any, V any] interface {
+	Reader[K, V]
+	Writer[K, V]
+}
+
+// adapter implements the `ReadWriter` interface by the function pointers of the facade.
+type adapter[K comparable, V any] struct {
+	m *HashMap[K, V]
+}
+
+func (a adapter[K, V]) Size() int                       { return a.m.Size() }
+func (a adapter[K, V]) Each(fn func(key K, val V) bool) { a.m.Each(fn) }
+func (a adapter[K, V]) Put(key K, val V) bool           { return a.m.Put(key, val) }
+func (a adapter[K, V]) Reserve(n uintptr)               { a.m.Reserve(n) }
+
+// Adapt returns the `HashMap` facade as `ReadWriter`, so it can be used
+// with the helper functions of this package.
+//
+// Deprecated: `Map` implements `ReadWriter`.
+func Adapt[K comparable, V any](m *HashMap[K, V]) ReadWriter[K, V] {
+	return adapter[K, V]{m: m}
+}
+
+// All returns an iterator over all key-value pairs of the hashmap.
+// The hashmap must not be modified during the iteration.
+func All[K any, V any](src Reader[K, V]) iter.Seq2[K, V] {
+	return func(yield func(key K, val V) bool) {
+		src.Each(func(key K, val V) bool {
+			return !yield(key, val)
+		})
+	}
+}
+
+// Mutable is implemented by the hashmaps, which allow to modify
+// the values in-place during the iteration.
+type Mutable[K any, V any] interface {
+	EachMut(fn func(key K, val *V) bool)
+}
+
+// AllMut returns an iterator over all keys and pointers to their values.
+// The values can be modified through the pointers, but structural changes
+// of the hashmap during the iteration panic with `shared.ErrIterating`.
+func AllMut[K any, V any](src Mutable[K, V]) iter.Seq2[K, *V] {
+	return func(yield func(key K, val *V) bool) {
+		src.EachMut(func(key K, val *V) bool {
+			return !yield(key, val)
+		})
+	}
+}
+
+// FromMap puts all key-value pairs of the golang map into 'dst', which is
+// sized by `Reserve` before. It returns 'dst'.
+func FromMap[M Writer[K, V], K comparable, V any](dst M, src map[K]V) M {
+	dst.Reserve(uintptr(len(src)))
+
+	for key, val := range src {
+		dst.Put(key, val)
+	}
+
+	return dst
+}
+
+// ToMap returns a golang map with all key-value pairs of the hashmap.
+func ToMap[K comparable, V any](src Reader[K, V]) map[K]V {
+	res := make(map[K]V, src.Size())
+
+	src.Each(func(key K, val V) bool {
+		res[key] = val
+		return false
+	})
+
+	return res
+}
+
+// Collect creates a hashmap by the given config and puts all
+// key-value pairs of 'seq' into it.
+func Collect[K comparable, V any](cfg Config[K, V], seq iter.Seq2[K, V]) (Map[K, V], error) {
+	m, err := NewHashMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	Insert(m, seq)
+
+	return m, nil
+}
+
+// Insert puts all key-value pairs of 'seq' into 'dst'. Existing keys
+// are overwritten. It returns the number of new keys.
+func Insert[K any, V any](dst Writer[K, V], seq iter.Seq2[K, V]) int {
+	added := 0
+
+	for key, val := range seq {
+		if dst.Put(key, val) {
+			added++
+		}
+	}
+
+	return added
+}
+
+// Clone puts all key-value pairs of 'src' into 'dst', which is sized by
+// `Reserve` before. The hashmaps can be of different types. It returns 'dst'.
+func Clone[M Writer[K, V], K any, V any](dst M, src Reader[K, V]) M {
+	dst.Reserve(uintptr(src.Size()))
+
+	src.Each(func(key K, val V) bool {
+		dst.Put(key, val)
+		return false
+	})
+
+	return dst
+}
+
+// Keys returns the keys of the hashmap in the order of `Each`.
+func Keys[K any, V any](src Reader[K, V]) []K {
+	res := make([]K, 0, src.Size())
+
+	src.Each(func(key K, _ V) bool {
+		res = append(res, key)
+		return false
+	})
+
+	return res
+}
+
+// Values returns the values of the hashmap in the order of `Each`.
+func Values[K any, V any](src Reader[K, V]) []V {
+	res := make([]V, 0, src.Size())
+
+	src.Each(func(_ K, val V) bool {
+		res = append(res, val)
+		return false
+	})
+
+	return res
+}
@@ -0,0 +1,380 @@
+package concurrent
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+const (
+	// MaxValue is the largest value, that can be stored in a `LockFree` hashmap.
+	// The two most significant bits of a value word are reserved.
+	MaxValue = 1<<62 - 1
+
+	// setBit marks a value word, that holds a value
+	setBit = 1 << 62
+	// frozenBit marks a value word, that is migrated to the next table
+	frozenBit = 1 << 63
+
+	// emptyKey marks a free key word, the key 0 is stored separately
+	emptyKey = 0
+	// copyChunk is the number of slots, that are migrated at once
+	copyChunk = 1024
+	// lockFreeMinSize is the minimum number of slots of a table
+	lockFreeMinSize = 16
+)
+
+// lockFreeTable is one generation of the `LockFree` hashmap. The key words
+// are claimed once and never released, the value words are modified by CAS.
+type lockFreeTable struct {
+	keys []atomic.Uint64
+	vals []atomic.Uint64
+	mask uintptr
+	// claimed is the number of used key words
+	claimed atomic.Int64
+	// next is set, if the table is migrated into a larger one
+	next atomic.Pointer[lockFreeTable]
+	// copyIdx is the next chunk of slots to migrate
+	copyIdx atomic.Int64
+	// copyDone is the number of migrated slots
+	copyDone atomic.Int64
+}
+
+func newLockFreeTable(n uintptr) *lockFreeTable {
+	return &lockFreeTable{
+		keys: make([]atomic.Uint64, n),
+		vals: make([]atomic.Uint64, n),
+		mask: n - 1,
+	}
+}
+
+// reprobeLimit bounds the probing, a longer probe starts a resize.
+//
+//go:inline
+func (t *lockFreeTable) reprobeLimit() int {
+	return 10 + len(t.keys)>>2
+}
+
+// maxClaimed is the number of claimed keys, from which on the table is resized.
+//
+//go:inline
+func (t *lockFreeTable) maxClaimed() int64 {
+	return int64(shared.Threshold(uintptr(len(t.keys)), shared.DefaultMaxLoad))
+}
+
+// LockFree is a hashmap for uint64 keys and values, which is safe for concurrent
+// use without any locks. It uses linear probing, where each key word is claimed
+// by an atomic compare-and-swap (CAS) and never released. The values are updated
+// by CAS as well. If the table becomes full, a larger table is allocated and all
+// writers migrate the old table cooperatively in chunks, while the readers keep
+// going. The design follows the lock-free hashmap of Cliff Click.
+// Keys can not be removed and the values are limited to `MaxValue`.
+type LockFree struct {
+	current atomic.Pointer[lockFreeTable]
+	hasher  shared.HashFn[uint64]
+	// zero stores the value word of the key 0
+	zero atomic.Uint64
+	size atomic.Int64
+}
+
+// NewLockFree creates a ready to use `LockFree` hashmap.
+func NewLockFree() *LockFree {
+	return NewLockFreeWithSize(lockFreeMinSize)
+}
+
+// NewLockFreeWithSize same as `NewLockFree` but it is sized for n elements.
+func NewLockFreeWithSize(n uintptr) *LockFree {
+	size := shared.Capacity(n, shared.DefaultMaxLoad)
+
+	if size < lockFreeMinSize {
+		size = lockFreeMinSize
+	}
+
+	m := &LockFree{
+		hasher: shared.GetHasher[uint64](),
+	}
+	m.current.Store(newLockFreeTable(size))
+
+	return m
+}
+
+//go:inline
+func checkValue(val uint64) {
+	if val > MaxValue {
+		panic(fmt.Sprintf("value %d exceeds %d", val, uint64(MaxValue)))
+	}
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (m *LockFree) Get(key uint64) (uint64, bool) {
+	if key == emptyKey {
+		v := m.zero.Load()
+		return v &^ setBit, v&setBit != 0
+	}
+
+	hash := m.hasher(key)
+
+	for t := m.current.Load(); t != nil; t = t.next.Load() {
+		idx := t.find(key, hash, false)
+		if idx < 0 {
+			// the key may be inserted in the next table
+			continue
+		}
+
+		v := t.vals[idx].Load()
+		if v&frozenBit != 0 {
+			m.copySlot(t, idx)
+			continue
+		}
+
+		if v&setBit != 0 {
+			return v &^ setBit, true
+		}
+
+		// the key is claimed, but not set
+		return 0, false
+	}
+
+	return 0, false
+}
+
+// Size returns the number of items in the hashmap.
+func (m *LockFree) Size() int {
+	return int(m.size.Load())
+}
+
+// PutIfAbsent stores the value, if the key is not in the hashmap. It returns the
+// stored value and true, if the value was already in, otherwise `val` and false.
+// It panics, if `val` exceeds `MaxValue`.
+func (m *LockFree) PutIfAbsent(key uint64, val uint64) (uint64, bool) {
+	checkValue(val)
+
+	var (
+		actual uint64
+		loaded bool
+	)
+
+	m.update(key, func(v uint64) (uint64, bool) {
+		if v&setBit != 0 {
+			actual, loaded = v&^setBit, true
+			return 0, false
+		}
+
+		actual, loaded = val, false
+
+		return val | setBit, true
+	})
+
+	return actual, loaded
+}
+
+// Add adds `delta` to the value of the key and returns the new value. A missing
+// key is inserted with the value `delta`. The values wrap around at `MaxValue`,
+// so a subtraction can be done by adding the two's complement.
+func (m *LockFree) Add(key uint64, delta uint64) uint64 {
+	var res uint64
+
+	m.update(key, func(v uint64) (uint64, bool) {
+		res = (v + delta) & MaxValue
+		return res | setBit, true
+	})
+
+	return res
+}
+
+// update applies 'fn' by CAS on the value word of the key. 'fn' gets the current
+// value word and returns the new one or false, if nothing is to change.
+func (m *LockFree) update(key uint64, fn func(v uint64) (uint64, bool)) {
+	if key == emptyKey {
+		for {
+			v := m.zero.Load()
+
+			n, ok := fn(v)
+			if !ok {
+				return
+			}
+
+			if m.zero.CompareAndSwap(v, n) {
+				if v&setBit == 0 {
+					m.size.Add(1)
+				}
+
+				return
+			}
+		}
+	}
+
+	var (
+		hash = m.hasher(key)
+		t    = m.current.Load()
+	)
+
+	for {
+		next := t.next.Load()
+		if next != nil {
+			m.helpCopy(t)
+		}
+
+		idx := t.find(key, hash, true)
+		if idx < 0 {
+			// the table is full
+			t = m.resize(t)
+			continue
+		}
+
+		if next == nil && t.claimed.Load() > t.maxClaimed() {
+			next = m.resize(t)
+		}
+
+		if next != nil {
+			// the writes are done in the next table, after the slot is migrated
+			m.copySlot(t, idx)
+			t = next
+
+			continue
+		}
+
+		for {
+			v := t.vals[idx].Load()
+			if v&frozenBit != 0 {
+				m.copySlot(t, idx)
+				t = t.next.Load()
+
+				break
+			}
+
+			n, ok := fn(v)
+			if !ok {
+				return
+			}
+
+			if t.vals[idx].CompareAndSwap(v, n) {
+				if v&setBit == 0 {
+					m.size.Add(1)
+				}
+
+				return
+			}
+		}
+	}
+}
+
+// find returns the slot of the key or -1. If `claim` is set, a free slot is
+// claimed for the key. -1 is also returned, if the reprobe limit is reached.
+func (t *lockFreeTable) find(key uint64, hash uintptr, claim bool) int {
+	idx := hash & t.mask
+
+	for probes := 0; probes < t.reprobeLimit(); probes++ {
+		k := t.keys[idx].Load()
+		if k == emptyKey {
+			if !claim {
+				return -1
+			}
+
+			if t.keys[idx].CompareAndSwap(emptyKey, key) {
+				t.claimed.Add(1)
+				return int(idx)
+			}
+
+			// another key was claimed concurrently
+			k = t.keys[idx].Load()
+		}
+
+		if k == key {
+			return int(idx)
+		}
+		// next index
+		idx = (idx + 1) & t.mask
+	}
+
+	return -1
+}
+
+// resize returns the next table of `t`, which is allocated if needed.
+func (m *LockFree) resize(t *lockFreeTable) *lockFreeTable {
+	if next := t.next.Load(); next != nil {
+		return next
+	}
+
+	next := newLockFreeTable(uintptr(len(t.keys)) * 2)
+	if !t.next.CompareAndSwap(nil, next) {
+		// another writer was faster
+		return t.next.Load()
+	}
+
+	return next
+}
+
+// copySlot freezes the slot of `t` and copies its value into the next table.
+// The copy is a put if absent, so it can be done by several goroutines.
+// A slot is only frozen, if the next table exists.
+func (m *LockFree) copySlot(t *lockFreeTable, idx int) {
+	var v uint64
+
+	for {
+		v = t.vals[idx].Load()
+		if v&frozenBit != 0 {
+			break
+		}
+
+		if t.vals[idx].CompareAndSwap(v, v|frozenBit) {
+			v |= frozenBit
+			break
+		}
+	}
+
+	if v&setBit != 0 {
+		m.copyValue(t.next.Load(), t.keys[idx].Load(), v&^frozenBit)
+	}
+}
+
+// copyValue inserts the value word into the table `t`, if the key is absent.
+func (m *LockFree) copyValue(t *lockFreeTable, key uint64, val uint64) {
+	hash := m.hasher(key)
+
+	for {
+		idx := t.find(key, hash, true)
+		if idx < 0 {
+			t = m.resize(t)
+			continue
+		}
+
+		for {
+			v := t.vals[idx].Load()
+			if v&frozenBit != 0 {
+				m.copySlot(t, idx)
+				t = t.next.Load()
+
+				break
+			}
+
+			if v&setBit != 0 || t.vals[idx].CompareAndSwap(v, val) {
+				return
+			}
+		}
+	}
+}
+
+// helpCopy migrates one chunk of the table into the next table and
+// promotes the next table, if the migration is done.
+func (m *LockFree) helpCopy(t *lockFreeTable) {
+	n := int64(len(t.keys))
+
+	if lo := t.copyIdx.Add(copyChunk) - copyChunk; lo < n {
+		hi := lo + copyChunk
+		if hi > n {
+			hi = n
+		}
+
+		for idx := lo; idx < hi; idx++ {
+			m.copySlot(t, int(idx))
+		}
+
+		t.copyDone.Add(hi - lo)
+	}
+
+	if t.copyDone.Load() == n {
+		m.current.CompareAndSwap(t, t.next.Load())
+	}
+}
@@ -0,0 +1,130 @@
+package concurrent_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EinfachAndy/hashmaps/concurrent"
+)
+
+func TestLockFreeSimple(t *testing.T) {
+	t.Parallel()
+
+	m := concurrent.NewLockFree()
+
+	for _, k := range []uint64{0, 1, 1 << 63, ^uint64(0)} {
+		_, found := m.Get(k)
+		assert.False(t, found)
+
+		v, loaded := m.PutIfAbsent(k, 7)
+		assert.False(t, loaded)
+		assert.Equal(t, uint64(7), v)
+
+		v, loaded = m.PutIfAbsent(k, 8)
+		assert.True(t, loaded)
+		assert.Equal(t, uint64(7), v)
+
+		assert.Equal(t, uint64(10), m.Add(k, 3))
+		// subtraction by the two's complement
+		assert.Equal(t, uint64(9), m.Add(k, ^uint64(0)))
+
+		v, found = m.Get(k)
+		assert.True(t, found)
+		assert.Equal(t, uint64(9), v)
+	}
+
+	assert.Equal(t, 4, m.Size())
+	assert.Panics(t, func() { m.PutIfAbsent(2, concurrent.MaxValue+1) })
+}
+
+func TestLockFreeAddStress(t *testing.T) {
+	t.Parallel()
+
+	const (
+		workers = 8
+		keys    = 5000
+		rounds  = 3
+	)
+
+	var (
+		m  = concurrent.NewLockFree()
+		wg sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for r := 0; r < rounds; r++ {
+				for k := uint64(0); k < keys; k++ {
+					// every worker starts at a different key
+					key := (k + uint64(w)*keys/workers) % keys
+					m.Add(key, 1)
+
+					v, found := m.Get(key)
+					assert.True(t, found)
+					assert.True(t, v > 0)
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, keys, m.Size())
+
+	for k := uint64(0); k < keys; k++ {
+		v, found := m.Get(k)
+		assert.True(t, found)
+		assert.Equal(t, uint64(workers*rounds), v)
+	}
+}
+
+func TestLockFreePutIfAbsentStress(t *testing.T) {
+	t.Parallel()
+
+	const (
+		workers = 8
+		keys    = 20000
+	)
+
+	var (
+		m       = concurrent.NewLockFree()
+		wg      sync.WaitGroup
+		winners [workers]int
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for k := uint64(1); k <= keys; k++ {
+				v, loaded := m.PutIfAbsent(k, uint64(w))
+				if !loaded {
+					winners[w]++
+				}
+
+				// all workers agree on the winner
+				got, found := m.Get(k)
+				assert.True(t, found)
+				assert.Equal(t, v, got)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	total := 0
+	for _, n := range winners {
+		total += n
+	}
+
+	assert.Equal(t, keys, total)
+	assert.Equal(t, keys, m.Size())
+}
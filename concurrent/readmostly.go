@@ -0,0 +1,200 @@
+// Package concurrent collects hashmaps, which are safe for concurrent use.
+package concurrent
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/EinfachAndy/hashmaps/flat"
+	"github.com/EinfachAndy/hashmaps/robin"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// table is a hashmap, that is used as version of a `ReadMostly` hashmap.
+type table[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Put(key K, val V) bool
+	Remove(key K) bool
+	Clear()
+	Size() int
+	Each(fn func(key K, val V) bool)
+	clone() table[K, V]
+}
+
+type robinTable[K comparable, V any] struct {
+	*robin.RobinHood[K, V]
+}
+
+func (t robinTable[K, V]) clone() table[K, V] {
+	return robinTable[K, V]{t.Copy()}
+}
+
+type flatTable[K comparable, V any] struct {
+	*flat.Flat[K, V]
+}
+
+func (t flatTable[K, V]) clone() table[K, V] {
+	return flatTable[K, V]{t.Copy()}
+}
+
+// ReadMostly is a hashmap for read-mostly workloads, which is safe for
+// concurrent use. It follows the read-copy-update (RCU) pattern: the readers
+// access an immutable version of the hashmap through an atomic pointer, so
+// `Get` is wait-free and never blocks. A writer copies the current version,
+// applies its changes and publishes the copy as new version. Because each
+// modification costs O(n), the changes should be batched with `Update`.
+type ReadMostly[K comparable, V any] struct {
+	current atomic.Pointer[table[K, V]]
+	// mu serializes the writers
+	mu sync.Mutex
+}
+
+// NewReadMostly creates a ready to use `ReadMostly` hashmap,
+// which uses a `robin.RobinHood` hashmap as version.
+func NewReadMostly[K comparable, V any]() *ReadMostly[K, V] {
+	return NewReadMostlyWithHasher[K, V](shared.GetHasher[K]())
+}
+
+// NewReadMostlyWithHasher same as `NewReadMostly` but with a given hash function.
+func NewReadMostlyWithHasher[K comparable, V any](hasher shared.HashFn[K]) *ReadMostly[K, V] {
+	return newReadMostly[K, V](robinTable[K, V]{robin.NewWithHasher[K, V](hasher)})
+}
+
+// NewReadMostlyFlat creates a ready to use `ReadMostly` hashmap, which uses
+// a `flat.Flat` hashmap as version. See `flat.NewWithHasher` for the
+// meaning of `empty`.
+func NewReadMostlyFlat[K comparable, V any](empty K, hasher shared.HashFn[K]) *ReadMostly[K, V] {
+	return newReadMostly[K, V](flatTable[K, V]{flat.NewWithHasher[K, V](empty, hasher)})
+}
+
+func newReadMostly[K comparable, V any](t table[K, V]) *ReadMostly[K, V] {
+	m := &ReadMostly[K, V]{}
+	m.current.Store(&t)
+
+	return m
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+// It never blocks.
+func (m *ReadMostly[K, V]) Get(key K) (V, bool) {
+	return (*m.current.Load()).Get(key)
+}
+
+// Size returns the number of items in the current version.
+func (m *ReadMostly[K, V]) Size() int {
+	return (*m.current.Load()).Size()
+}
+
+// Each calls 'fn' on every key-value pair of the current version in no particular
+// order. Modifications within 'fn' are not visible for the iteration.
+// If 'fn' returns true, the iteration stops.
+func (m *ReadMostly[K, V]) Each(fn func(key K, val V) bool) {
+	(*m.current.Load()).Each(fn)
+}
+
+// Put adds the given key-value pair and publishes a new version.
+// Returns true, if the element is a new item in the hashmap.
+func (m *ReadMostly[K, V]) Put(key K, val V) bool {
+	var isNew bool
+
+	_ = m.Update(func(tx *Tx[K, V]) error {
+		isNew = tx.Put(key, val)
+		return nil
+	})
+
+	return isNew
+}
+
+// Remove removes the specified key and publishes a new version.
+// Returns true, if the element was in the hashmap.
+func (m *ReadMostly[K, V]) Remove(key K) bool {
+	var removed bool
+
+	_ = m.Update(func(tx *Tx[K, V]) error {
+		removed = tx.Remove(key)
+		return nil
+	})
+
+	return removed
+}
+
+// Update applies all modifications of 'fn' atomically: the readers see either
+// none or all of them. The new version is published, after 'fn' returns. If 'fn'
+// returns an error, the modifications are discarded and the error is returned.
+// Concurrent updates are serialized. The transaction must not be used after
+// 'fn' returns.
+func (m *ReadMostly[K, V]) Update(fn func(tx *Tx[K, V]) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &Tx[K, V]{
+		table: *m.current.Load(),
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if tx.copied {
+		m.current.Store(&tx.table)
+	}
+
+	return nil
+}
+
+// Tx is a transaction of `ReadMostly.Update`. It reads its own modifications.
+// The current version is copied on the first modification.
+type Tx[K comparable, V any] struct {
+	table  table[K, V]
+	copied bool
+}
+
+//go:inline
+func (tx *Tx[K, V]) write() {
+	if !tx.copied {
+		tx.table = tx.table.clone()
+		tx.copied = true
+	}
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	return tx.table.Get(key)
+}
+
+// Put adds the given key-value pair to the hashmap. If the key already exists its
+// value will be overwritten with the new value.
+// Returns true, if the element is a new item in the hashmap.
+func (tx *Tx[K, V]) Put(key K, val V) bool {
+	tx.write()
+	return tx.table.Put(key, val)
+}
+
+// Remove removes the specified key-value pair from the hashmap.
+// Returns true, if the element was in the hashmap.
+func (tx *Tx[K, V]) Remove(key K) bool {
+	if _, found := tx.table.Get(key); !found {
+		return false
+	}
+
+	tx.write()
+
+	return tx.table.Remove(key)
+}
+
+// Clear removes all key-value pairs from the hashmap.
+func (tx *Tx[K, V]) Clear() {
+	tx.write()
+	tx.table.Clear()
+}
+
+// Size returns the number of items in the hashmap.
+func (tx *Tx[K, V]) Size() int {
+	return tx.table.Size()
+}
+
+// Each calls 'fn' on every key-value pair in the hash map in no particular order.
+// If 'fn' returns true, the iteration stops.
+func (tx *Tx[K, V]) Each(fn func(key K, val V) bool) {
+	tx.table.Each(fn)
+}
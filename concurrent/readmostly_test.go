@@ -0,0 +1,143 @@
+package concurrent_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EinfachAndy/hashmaps/concurrent"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+func setupReadMostly() []*concurrent.ReadMostly[int, int] {
+	return []*concurrent.ReadMostly[int, int]{
+		concurrent.NewReadMostly[int, int](),
+		concurrent.NewReadMostlyFlat[int, int](-1, shared.GetHasher[int]()),
+	}
+}
+
+func TestReadMostlyUpdate(t *testing.T) {
+	t.Parallel()
+
+	for _, m := range setupReadMostly() {
+		assert.True(t, m.Put(1, 1))
+		assert.False(t, m.Put(1, 2))
+		assert.True(t, m.Remove(1))
+		assert.False(t, m.Remove(1))
+		assert.Equal(t, 0, m.Size())
+
+		err := m.Update(func(tx *concurrent.Tx[int, int]) error {
+			for i := 0; i < 100; i++ {
+				tx.Put(i, i)
+			}
+
+			// the transaction reads its own modifications
+			v, ok := tx.Get(50)
+			assert.True(t, ok)
+			assert.Equal(t, 50, v)
+			assert.Equal(t, 100, tx.Size())
+
+			// but they are not visible before the commit
+			assert.Equal(t, 0, m.Size())
+
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 100, m.Size())
+
+		// a failed transaction is discarded
+		errAbort := errors.New("abort")
+		err = m.Update(func(tx *concurrent.Tx[int, int]) error {
+			tx.Clear()
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+		assert.Equal(t, 100, m.Size())
+
+		v, ok := m.Get(99)
+		assert.True(t, ok)
+		assert.Equal(t, 99, v)
+	}
+}
+
+func TestReadMostlyConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		accounts = 64
+		balance  = 100
+		readers  = 4
+		updates  = 500
+	)
+
+	for _, m := range setupReadMostly() {
+		_ = m.Update(func(tx *concurrent.Tx[int, int]) error {
+			for i := 0; i < accounts; i++ {
+				tx.Put(i, balance)
+			}
+
+			return nil
+		})
+
+		var (
+			readWg  sync.WaitGroup
+			writeWg sync.WaitGroup
+			done    = make(chan struct{})
+		)
+
+		// the readers check, that each version is consistent
+		for r := 0; r < readers; r++ {
+			readWg.Add(1)
+
+			go func() {
+				defer readWg.Done()
+
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+
+					sum := 0
+					m.Each(func(_, v int) bool {
+						sum += v
+						return false
+					})
+					assert.Equal(t, accounts*balance, sum)
+
+					_, ok := m.Get(accounts / 2)
+					assert.True(t, ok)
+				}
+			}()
+		}
+
+		// the writers move money between the accounts
+		for w := 0; w < 2; w++ {
+			writeWg.Add(1)
+
+			go func(w int) {
+				defer writeWg.Done()
+
+				for i := 0; i < updates; i++ {
+					from, to := (i+w)%accounts, (i*7+w+1)%accounts
+
+					_ = m.Update(func(tx *concurrent.Tx[int, int]) error {
+						a, _ := tx.Get(from)
+						b, _ := tx.Get(to)
+						tx.Put(from, a-1)
+						tx.Put(to, b+1)
+
+						return nil
+					})
+				}
+			}(w)
+		}
+
+		writeWg.Wait()
+		close(done)
+		readWg.Wait()
+	}
+}
@@ -0,0 +1,294 @@
+// Package custom implements a hashmap with a custom key equality, which lifts
+// the `comparable` constraint of the keys. So slices like `[]byte` can be used
+// as keys or strings can be compared case insensitive, without canonicalizing
+// the keys before.
+package custom
+
+import (
+	"fmt"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+const (
+	emptyBucket = -1
+)
+
+// EqualFn reports whether the keys are equal. It must be an equivalence
+// relation and keys, which are equal, must have the same hash value.
+type EqualFn[K any] func(a, b K) bool
+
+type bucket[K any, V any] struct {
+	key   K
+	value V
+	// hash is compared before the keys, so `equal` is only
+	// called for the keys with the same hash value
+	hash uintptr
+	// psl is the probe sequence length, -1 or `emptyBucket` signals a free slot
+	psl int32
+}
+
+// Map is a hashmap, that uses linear probing in combination with robin hood
+// hashing like `robin.RobinHood`, but the keys are compared by a custom
+// `EqualFn`. The hash value of each key is stored in its bucket, which avoids
+// the calls of the hasher on resize and most calls of `equal` on lookups.
+type Map[K any, V any] struct {
+	buckets []bucket[K, V]
+	hasher  shared.HashFn[K]
+	equal   EqualFn[K]
+	// length stores the current inserted elements
+	length uintptr
+	// capMinus1 is used for a bitwise AND on the hash value,
+	// because the size of the underlying array is a power of two value
+	capMinus1  uintptr
+	nextResize uintptr
+	maxLoad    float32
+	// debug verifies the consistency of the hasher and `equal`, see `Debug`
+	debug bool
+}
+
+// New creates a ready to use `Map` with the given hash function and equality.
+func New[K any, V any](hasher shared.HashFn[K], equal EqualFn[K]) *Map[K, V] {
+	m := &Map[K, V]{
+		hasher:  hasher,
+		equal:   equal,
+		maxLoad: shared.DefaultMaxLoad,
+	}
+	m.Reserve(shared.DefaultSize)
+
+	return m
+}
+
+//go:inline
+func newBucketArray[K any, V any](capacity uintptr) []bucket[K, V] {
+	buckets := make([]bucket[K, V], capacity)
+
+	for i := range buckets {
+		buckets[i].psl = emptyBucket
+	}
+
+	return buckets
+}
+
+// Debug enables the verification of the hasher and the equality. In debug mode
+// each operation checks, that the hash value of the key is deterministic, the key
+// is equal to itself and the keys of the probed buckets, which are equal to the
+// key, have the same hash value. Otherwise it panics with ErrInconsistent.
+// Equal keys, which are not probed, are not detected.
+func (m *Map[K, V]) Debug(on bool) {
+	m.debug = on
+}
+
+// hash returns the hash value of the key and verifies it in debug mode.
+//
+//go:inline
+func (m *Map[K, V]) hash(key K) uintptr {
+	hash := m.hasher(key)
+
+	if m.debug {
+		if again := m.hasher(key); again != hash {
+			panic(fmt.Errorf("hash of key %v is not deterministic %x != %x: %w",
+				key, hash, again, shared.ErrInconsistent))
+		}
+
+		if !m.equal(key, key) {
+			panic(fmt.Errorf("key %v is not equal to itself: %w", key, shared.ErrInconsistent))
+		}
+	}
+
+	return hash
+}
+
+// search returns the index of the key and true, or the index and the
+// PSL, where the search stopped, and false if the key is not found.
+//
+//go:inline
+func (m *Map[K, V]) search(key K, hash uintptr) (uintptr, int32, bool) {
+	var (
+		idx = hash & m.capMinus1
+		psl = int32(0)
+	)
+
+	for ; psl <= m.buckets[idx].psl; psl++ {
+		b := &m.buckets[idx]
+
+		if b.hash == hash && m.equal(b.key, key) {
+			return idx, psl, true
+		}
+
+		if m.debug && m.equal(b.key, key) {
+			panic(fmt.Errorf("keys %v and %v are equal, but have the hash values %x != %x: %w",
+				b.key, key, b.hash, hash, shared.ErrInconsistent))
+		}
+		// next index
+		idx = (idx + 1) & m.capMinus1
+	}
+
+	return idx, psl, false
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if idx, _, found := m.search(key, m.hash(key)); found {
+		return m.buckets[idx].value, true
+	}
+
+	var v V
+
+	return v, false
+}
+
+// Reserve sets the number of buckets to the most appropriate to contain at least n elements.
+// If n is lower than that, the function may have no effect.
+func (m *Map[K, V]) Reserve(n uintptr) {
+	newCap := shared.Capacity(n, m.maxLoad)
+
+	if uintptr(len(m.buckets)) < newCap {
+		m.resize(newCap)
+	}
+}
+
+// resize rebuilds the hashmap with `n` buckets by the stored hash values.
+func (m *Map[K, V]) resize(n uintptr) {
+	oldBuckets := m.buckets
+
+	m.buckets = newBucketArray[K, V](n)
+	m.capMinus1 = n - 1
+	m.nextResize = shared.Threshold(n, m.maxLoad)
+
+	for i := range oldBuckets {
+		if oldBuckets[i].psl != emptyBucket {
+			current := oldBuckets[i]
+			current.psl = 0
+			m.emplace(&current, current.hash&m.capMinus1)
+		}
+	}
+}
+
+// Put adds the given key-value pair to the hashmap. If the key already exists its
+// value will be overwritten with the new value.
+// Returns true, if the element is a new item in the hashmap.
+func (m *Map[K, V]) Put(key K, val V) bool {
+	if m.length >= m.nextResize {
+		m.resize(uintptr(len(m.buckets)) * 2)
+	}
+
+	hash := m.hash(key)
+
+	idx, psl, found := m.search(key, hash)
+	if found {
+		m.buckets[idx].value = val
+		return false // update already existing value
+	}
+
+	m.length++
+
+	newBucket := bucket[K, V]{key: key, value: val, hash: hash, psl: psl}
+	m.emplace(&newBucket, idx)
+
+	return true
+}
+
+// emplace applies the Robin Hood creed to all following buckets until a empty is found.
+//
+//go:inline
+func (m *Map[K, V]) emplace(current *bucket[K, V], idx uintptr) {
+	for ; ; current.psl++ {
+		b := &m.buckets[idx]
+
+		if b.psl == emptyBucket {
+			*b = *current
+			return
+		}
+
+		if current.psl > b.psl {
+			*b, *current = *current, *b
+		}
+		// next index
+		idx = (idx + 1) & m.capMinus1
+	}
+}
+
+// Remove removes the specified key-value pair from the hashmap.
+// Returns true, if the element was in the hashmap.
+func (m *Map[K, V]) Remove(key K) bool {
+	idx, _, found := m.search(key, m.hash(key))
+	if !found {
+		return false
+	}
+
+	m.length--
+
+	// now, back shift all buckets until we found a optimum or empty one
+	for next := (idx + 1) & m.capMinus1; m.buckets[next].psl > 0; next = (next + 1) & m.capMinus1 {
+		m.buckets[idx] = m.buckets[next]
+		m.buckets[idx].psl--
+		idx = next
+	}
+
+	// the bucket is zeroed, so the key and value can be released
+	m.buckets[idx] = bucket[K, V]{psl: emptyBucket}
+
+	return true
+}
+
+// Clear removes all key-value pairs from the hashmap.
+func (m *Map[K, V]) Clear() {
+	for i := range m.buckets {
+		m.buckets[i] = bucket[K, V]{psl: emptyBucket}
+	}
+
+	m.length = 0
+}
+
+// Size returns the number of items in the hashmap.
+func (m *Map[K, V]) Size() int {
+	return int(m.length)
+}
+
+// Load return the current load of the hashmap.
+func (m *Map[K, V]) Load() float32 {
+	return float32(m.length) / float32(len(m.buckets))
+}
+
+// MaxLoad forces resizing if the ratio is reached.
+// Useful values are in range [0.5-0.9].
+// Returns ErrOutOfRange if `lf` is not in the open range (0.0,1.0).
+func (m *Map[K, V]) MaxLoad(lf float32) error {
+	if lf <= 0.0 || lf >= 1.0 {
+		return fmt.Errorf("%f: %w", lf, shared.ErrOutOfRange)
+	}
+
+	m.maxLoad = lf
+	m.nextResize = shared.Threshold(uintptr(len(m.buckets)), lf)
+
+	return nil
+}
+
+// Copy returns a copy of this hashmap. The keys and values are copied
+// shallow, so slices as keys share their elements with the copy.
+func (m *Map[K, V]) Copy() *Map[K, V] {
+	return &Map[K, V]{
+		buckets:    append([]bucket[K, V](nil), m.buckets...),
+		hasher:     m.hasher,
+		equal:      m.equal,
+		length:     m.length,
+		capMinus1:  m.capMinus1,
+		nextResize: m.nextResize,
+		maxLoad:    m.maxLoad,
+		debug:      m.debug,
+	}
+}
+
+// Each calls 'fn' on every key-value pair in the hashmap in no particular order.
+// If 'fn' returns true, the iteration stops.
+func (m *Map[K, V]) Each(fn func(key K, val V) bool) {
+	for i := range m.buckets {
+		if m.buckets[i].psl != emptyBucket {
+			if stop := fn(m.buckets[i].key, m.buckets[i].value); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+}
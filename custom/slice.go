@@ -0,0 +1,40 @@
+package custom
+
+import (
+	"bytes"
+	"slices"
+	"unsafe"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// NewBytes creates a ready to use `Map` with byte slices as keys, which are
+// equal by their content. The keys must not be modified after insertion.
+func NewBytes[V any]() *Map[[]byte, V] {
+	hasher := shared.GetHasher[string]()
+
+	return New[[]byte, V](func(key []byte) uintptr {
+		return hasher(unsafe.String(unsafe.SliceData(key), len(key)))
+	}, bytes.Equal)
+}
+
+// NewSlice creates a ready to use `Map` with slices of golang default types as
+// keys, which are equal by their elements. The keys must not be modified after
+// insertion.
+func NewSlice[E comparable, V any]() *Map[[]E, V] {
+	return New[[]E, V](SliceHasher(shared.GetHasher[E]()), slices.Equal[[]E])
+}
+
+// SliceHasher combines the hash values of the elements to the hash value of the slice.
+func SliceHasher[E any](hasher shared.HashFn[E]) shared.HashFn[[]E] {
+	return func(key []E) uintptr {
+		h := uintptr(len(key))
+
+		for i := range key {
+			// combine function of boost
+			h ^= hasher(key[i]) + 0x9e3779b9 + h<<6 + h>>2
+		}
+
+		return h
+	}
+}
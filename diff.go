@@ -0,0 +1,108 @@
+package hashmaps
+
+// Equal returns true, if both hashmaps contain the same keys and the values
+// of each key are equal by 'eq'. The hashmaps can be of different types.
+// It returns false without iterating, if the sizes mismatch.
+func Equal[K comparable, V any](a, b Map[K, V], eq func(V, V) bool) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+
+	equal := true
+
+	a.Each(func(key K, val V) bool {
+		other, found := b.Get(key)
+		equal = found && eq(val, other)
+
+		return !equal
+	})
+
+	return equal
+}
+
+// Delta describes the differences from one hashmap to another, see `Diff`.
+// The keys are computed lazily by iterators, which follow the convention of
+// the golang iter package: the iteration stops, if 'yield' returns false.
+// The hashmaps must not be modified during an iteration.
+type Delta[K comparable, V any] struct {
+	// Added yields the keys, which are only in the second hashmap.
+	Added func(yield func(key K) bool)
+	// Removed yields the keys, which are only in the first hashmap.
+	Removed func(yield func(key K) bool)
+	// Changed yields the keys, which are in both hashmaps with different values.
+	Changed func(yield func(key K) bool)
+
+	to Map[K, V]
+}
+
+// Diff returns the differences from hashmap `a` to hashmap `b`.
+// The hashmaps can be of different types.
+func Diff[K comparable, V comparable](a, b Map[K, V]) Delta[K, V] {
+	return DiffFunc(a, b, func(x, y V) bool { return x == y })
+}
+
+// DiffFunc same as `Diff` but the values are compared by 'eq'.
+func DiffFunc[K comparable, V any](a, b Map[K, V], eq func(V, V) bool) Delta[K, V] {
+	return Delta[K, V]{
+		Added:   missing(b, a),
+		Removed: missing(a, b),
+		Changed: func(yield func(key K) bool) {
+			a.Each(func(key K, val V) bool {
+				if other, found := b.Get(key); found && !eq(val, other) {
+					return !yield(key)
+				}
+
+				return false
+			})
+		},
+		to: b,
+	}
+}
+
+// missing returns an iterator over the keys of `a`, which are not in `b`.
+func missing[K comparable, V any](a, b Map[K, V]) func(yield func(key K) bool) {
+	return func(yield func(key K) bool) {
+		a.Each(func(key K, _ V) bool {
+			if _, found := b.Get(key); !found {
+				return !yield(key)
+			}
+
+			return false
+		})
+	}
+}
+
+// Apply patches the hashmap `m`, so that it contains the same key-value pairs
+// as the second hashmap of the diff afterwards. It is applied usually to the
+// first hashmap of the diff, the second one must not be `m`.
+func (d Delta[K, V]) Apply(m Map[K, V]) {
+	// the keys are collected, because `m` may be iterated by `Removed` and `Changed`
+	var removed, changed []K
+
+	d.Removed(func(key K) bool {
+		removed = append(removed, key)
+		return true
+	})
+
+	d.Changed(func(key K) bool {
+		changed = append(changed, key)
+		return true
+	})
+
+	for _, key := range removed {
+		m.Remove(key)
+	}
+
+	put := func(key K) bool {
+		val, _ := d.to.Get(key)
+		m.Put(key, val)
+
+		return true
+	}
+
+	for _, key := range changed {
+		put(key)
+	}
+
+	d.Added(put)
+}
@@ -12,9 +12,18 @@ type bucket[K comparable, V any] struct {
 }
 
 // Flat is a open addressing hashmap implementation which uses linear probing
-// to solve conflicts.
+// to solve conflicts. Large values can be stored in a separate array, see `Layout`.
 type Flat[K comparable, V any] struct {
-	buckets   []bucket[K, V]
+	core[K, V, shared.AoSStorage]
+}
+
+// core implements the `Flat` hashmap for the storage S of the buckets.
+type core[K comparable, V any, S shared.Storage] struct {
+	// soa is set, if the hashmap uses the `shared.SoA` layout.
+	// All operations are forwarded to it.
+	soa *core[K, V, shared.SoAStorage]
+
+	table     table[K, V, S]
 	empty     K
 	hasher    shared.HashFn[K]
 	capMinus1 uintptr
@@ -22,6 +31,10 @@ type Flat[K comparable, V any] struct {
 
 	nextResize uintptr
 	maxLoad    float32
+	// sortKeys sorts the keys of `MarshalJSON`
+	sortKeys bool
+	// guard rejects structural changes during `EachMut`
+	guard shared.Guard
 }
 
 //go:inline
@@ -58,18 +71,32 @@ func New[K comparable, V any]() *Flat[K, V] {
 // NewWithHasher constructs a new hashmap with the given hasher.
 // Furthermore the representation for a empty bucket can be set.
 func NewWithHasher[K comparable, V any](empty K, hasher shared.HashFn[K]) *Flat[K, V] {
-	m := &Flat[K, V]{
-		hasher:  hasher,
-		maxLoad: shared.DefaultMaxLoad,
-		empty:   empty,
+	m := &Flat[K, V]{}
+
+	if soa, _ := shared.UseSoA[V](shared.AutoLayout); soa {
+		m.soa = &core[K, V, shared.SoAStorage]{}
+		m.soa.init(empty, hasher)
+	} else {
+		m.init(empty, hasher)
 	}
-	m.Reserve(shared.DefaultSize)
 
 	return m
 }
 
+func (m *core[K, V, S]) init(empty K, hasher shared.HashFn[K]) {
+	m.hasher = hasher
+	m.maxLoad = shared.DefaultMaxLoad
+	m.empty = empty
+
+	m.Reserve(shared.DefaultSize)
+}
+
 // Get returns the value stored for this key, or false if not found.
-func (m *Flat[K, V]) Get(key K) (V, bool) {
+func (m *core[K, V, S]) Get(key K) (V, bool) {
+	if m.soa != nil {
+		return m.soa.Get(key)
+	}
+
 	if key == m.empty {
 		panic(fmt.Sprintf("key %v is same as empty %v", key, m.empty))
 	}
@@ -80,9 +107,9 @@ func (m *Flat[K, V]) Get(key K) (V, bool) {
 		v    V
 	)
 
-	for m.buckets[idx].key != m.empty {
-		if m.buckets[idx].key == key {
-			return m.buckets[idx].value, true
+	for k := m.table.key(idx); k != m.empty; k = m.table.key(idx) {
+		if k == key {
+			return *m.table.value(idx), true
 		}
 
 		// next index
@@ -92,37 +119,46 @@ func (m *Flat[K, V]) Get(key K) (V, bool) {
 	return v, false
 }
 
-func (m *Flat[K, V]) resize(n uintptr) {
-	newm := Flat[K, V]{
+// resize rebuilds the hashmap with `n` buckets.
+func (m *core[K, V, S]) resize(n uintptr) {
+	newm := rebuild[S](m, n)
+
+	m.capMinus1 = newm.capMinus1
+	m.table = newm.table
+	m.nextResize = newm.nextResize
+}
+
+// rebuild returns a copy of the hashmap `m` with `n` buckets in the storage T.
+func rebuild[T, S shared.Storage, K comparable, V any](m *core[K, V, S], n uintptr) core[K, V, T] {
+	newm := core[K, V, T]{
 		capMinus1:  n - 1,
 		length:     m.length,
 		empty:      m.empty,
 		hasher:     m.hasher,
-		buckets:    newBucketArray[K, V](n, m.empty),
-		nextResize: uintptr(float32(n) * m.maxLoad),
+		table:      newTable[K, V, T](n, m.empty, m.table.gens.Enabled()),
+		nextResize: shared.Threshold(n, m.maxLoad),
 		maxLoad:    m.maxLoad,
+		sortKeys:   m.sortKeys,
 	}
 
-	for i := range m.buckets {
-		if m.buckets[i].key != m.empty {
-			newm.emplace(m.buckets[i].key, m.buckets[i].value)
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if k := m.table.key(i); k != m.empty {
+			newm.emplace(k, *m.table.value(i))
 		}
 	}
 
-	m.capMinus1 = newm.capMinus1
-	m.buckets = newm.buckets
-	m.nextResize = newm.nextResize
+	return newm
 }
 
 // emplace does not check if the key is already in.
-func (m *Flat[K, V]) emplace(key K, val V) {
+func (m *core[K, V, S]) emplace(key K, val V) {
 	var (
 		hash = m.hasher(key)
 		idx  = hash & m.capMinus1
 	)
 
 	for {
-		if m.buckets[idx].key == m.empty {
+		if m.table.key(idx) == m.empty {
 			break
 		}
 
@@ -131,19 +167,24 @@ func (m *Flat[K, V]) emplace(key K, val V) {
 	}
 
 	// we have a position for emplacing
-	m.buckets[idx].key = key
-	m.buckets[idx].value = val
+	m.table.store(idx, key, val)
 }
 
 // Put adds the given key-value pair to the hashmap. If the key already exists its
 // value will be overwritten with the new value.
-func (m *Flat[K, V]) Put(key K, val V) bool {
+func (m *core[K, V, S]) Put(key K, val V) bool {
+	if m.soa != nil {
+		return m.soa.Put(key, val)
+	}
+
+	m.guard.Check()
+
 	if key == m.empty {
 		panic(fmt.Sprintf("key %v is same as empty %v", key, m.empty))
 	}
 
 	if m.length >= m.nextResize {
-		m.resize(uintptr(cap(m.buckets)) * 2)
+		m.resize(m.table.len() * 2)
 	}
 
 	var (
@@ -151,24 +192,29 @@ func (m *Flat[K, V]) Put(key K, val V) bool {
 		idx  = hash & m.capMinus1
 	)
 
-	for m.buckets[idx].key != m.empty {
-		if m.buckets[idx].key == key {
-			m.buckets[idx].value = val
+	for k := m.table.key(idx); k != m.empty; k = m.table.key(idx) {
+		if k == key {
+			m.table.setValue(idx, val)
 			return false
 		}
 		// next index
 		idx = (idx + 1) & m.capMinus1
 	}
 
-	m.buckets[idx].key = key
-	m.buckets[idx].value = val
+	m.table.store(idx, key, val)
 	m.length++
 
 	return true
 }
 
 // Remove removes the specified key-value pair from the hashmap.
-func (m *Flat[K, V]) Remove(key K) bool {
+func (m *core[K, V, S]) Remove(key K) bool {
+	if m.soa != nil {
+		return m.soa.Remove(key)
+	}
+
+	m.guard.Check()
+
 	if key == m.empty {
 		panic(fmt.Sprintf("key %v is same as empty %v", key, m.empty))
 	}
@@ -178,27 +224,27 @@ func (m *Flat[K, V]) Remove(key K) bool {
 		idx  = hash & m.capMinus1
 	)
 
-	for m.buckets[idx].key != m.empty && !(m.buckets[idx].key == key) {
+	for m.table.key(idx) != m.empty && !(m.table.key(idx) == key) {
 		idx = (idx + 1) & m.capMinus1
 	}
 
-	if m.buckets[idx].key == m.empty {
+	if m.table.key(idx) == m.empty {
 		return false
 	}
 
-	m.buckets[idx].key = m.empty
+	m.table.setKey(idx, m.empty)
 	m.length--
 
 	// back shift elements to restore the search invariant
 	for {
 		idx = (idx + 1) & m.capMinus1
-		if m.buckets[idx].key == m.empty {
+		k := m.table.key(idx)
+		if k == m.empty {
 			break
 		}
 
-		k := m.buckets[idx].key
-		v := m.buckets[idx].value
-		m.buckets[idx].key = m.empty
+		v := *m.table.value(idx)
+		m.table.setKey(idx, m.empty)
 		m.emplace(k, v)
 	}
 
@@ -207,71 +253,162 @@ func (m *Flat[K, V]) Remove(key K) bool {
 
 // Reserve sets the number of buckets to the most appropriate to contain at least n elements.
 // If n is lower than that, the function may have no effect.
-func (m *Flat[K, V]) Reserve(n uintptr) {
-	var (
-		needed = uintptr(float32(n) / m.maxLoad)
-		newCap = uintptr(shared.NextPowerOf2(uint64(needed)))
-	)
+func (m *core[K, V, S]) Reserve(n uintptr) {
+	if m.soa != nil {
+		m.soa.Reserve(n)
+		return
+	}
+
+	m.guard.Check()
 
-	if uintptr(cap(m.buckets)) < newCap {
+	newCap := shared.Capacity(n, m.maxLoad)
+
+	if m.table.len() < newCap {
 		m.resize(newCap)
 	}
 }
 
 // Clear removes all key-value pairs from the hashmap.
-func (m *Flat[K, V]) Clear() {
-	for i := range m.buckets {
-		m.buckets[i].key = m.empty
+func (m *core[K, V, S]) Clear() {
+	if m.soa != nil {
+		m.soa.Clear()
+		return
 	}
 
+	m.guard.Check()
+	m.table.clear(m.empty)
+
 	m.length = 0
 }
 
+// LazyClear enables generation stamps, so `Clear` starts a new generation
+// in O(1) instead of walking all buckets. Buckets of an older generation
+// are treated as empty and the stamps are wiped every 255 calls of `Clear`.
+// The stamps cost one byte per bucket and the keys and values of the
+// cleared buckets are released only when they are overwritten.
+func (m *core[K, V, S]) LazyClear(on bool) {
+	if m.soa != nil {
+		m.soa.LazyClear(on)
+		return
+	}
+
+	m.table.lazyClear(on)
+}
+
 // Size returns the number of items in the hashmap.
-func (m *Flat[K, V]) Size() int {
+func (m *core[K, V, S]) Size() int {
+	if m.soa != nil {
+		return m.soa.Size()
+	}
+
 	return int(m.length)
 }
 
 // Load return the current load of the hashmap.
-func (m *Flat[K, V]) Load() float32 {
-	return float32(m.length) / float32(cap(m.buckets))
+func (m *core[K, V, S]) Load() float32 {
+	if m.soa != nil {
+		return m.soa.Load()
+	}
+
+	return float32(m.length) / float32(m.table.len())
 }
 
 // MaxLoad forces resizing if the ratio is reached.
 // Useful values are in range [0.5-0.7].
 // Returns ErrOutOfRange if `lf` is not in the open range (0.0,1.0).
-func (m *Flat[K, V]) MaxLoad(lf float32) error {
+func (m *core[K, V, S]) MaxLoad(lf float32) error {
+	if m.soa != nil {
+		return m.soa.MaxLoad(lf)
+	}
+
 	if lf <= 0.0 || lf >= 1.0 {
 		return fmt.Errorf("%f: %w", lf, shared.ErrOutOfRange)
 	}
 
 	m.maxLoad = lf
-	m.nextResize = uintptr(float32(cap(m.buckets)) * lf)
+	m.nextResize = shared.Threshold(m.table.len(), lf)
+
+	return nil
+}
+
+// Layout changes the memory layout of the buckets, see `shared.Layout`.
+// The hashmap is rebuilt, if the layout changes.
+// Returns ErrOutOfRange if `l` is unknown.
+func (m *Flat[K, V]) Layout(l shared.Layout) error {
+	soa, err := shared.UseSoA[V](l)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case soa && m.soa == nil:
+		m.guard.Check()
+
+		newm := rebuild[shared.SoAStorage](&m.core, m.table.len())
+		m.core = core[K, V, shared.AoSStorage]{soa: &newm}
+	case !soa && m.soa != nil:
+		m.soa.guard.Check()
+		m.core = rebuild[shared.AoSStorage](m.soa, m.soa.table.len())
+	}
 
 	return nil
 }
 
 func (m *Flat[K, V]) Copy() *Flat[K, V] {
-	newM := &Flat[K, V]{
-		buckets:    make([]bucket[K, V], uintptr(cap(m.buckets))),
+	if m.soa != nil {
+		newm := m.soa.copy()
+		return &Flat[K, V]{core[K, V, shared.AoSStorage]{soa: &newm}}
+	}
+
+	return &Flat[K, V]{m.copy()}
+}
+
+func (m *core[K, V, S]) copy() core[K, V, S] {
+	return core[K, V, S]{
+		table:      m.table.copy(),
 		capMinus1:  m.capMinus1,
 		length:     m.length,
 		hasher:     m.hasher,
 		empty:      m.empty,
 		nextResize: m.nextResize,
 		maxLoad:    m.maxLoad,
+		sortKeys:   m.sortKeys,
 	}
+}
 
-	copy(newM.buckets, m.buckets)
+// Each calls 'fn' on every key-value pair in the hashmap in no particular order.
+func (m *core[K, V, S]) Each(fn func(key K, val V) bool) {
+	if m.soa != nil {
+		m.soa.Each(fn)
+		return
+	}
 
-	return newM
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if k := m.table.key(i); k != m.empty {
+			if stop := fn(k, *m.table.value(i)); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
 }
 
-// Each calls 'fn' on every key-value pair in the hashmap in no particular order.
-func (m *Flat[K, V]) Each(fn func(key K, val V) bool) {
-	for i := range m.buckets {
-		if m.buckets[i].key != m.empty {
-			if stop := fn(m.buckets[i].key, m.buckets[i].value); stop {
+// EachMut calls 'fn' on every key-value pair in the hashmap in no particular order.
+// The value can be modified through the pointer, which is valid until 'fn' returns.
+// Structural changes like `Put`, `Remove` or `Clear` within 'fn' panic with
+// `shared.ErrIterating`. If 'fn' returns true, the iteration stops.
+func (m *core[K, V, S]) EachMut(fn func(key K, val *V) bool) {
+	if m.soa != nil {
+		m.soa.EachMut(fn)
+		return
+	}
+
+	m.guard.Enter()
+	defer m.guard.Leave()
+
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if k := m.table.key(i); k != m.empty {
+			if stop := fn(k, m.table.editValue(i)); stop {
 				// stop iteration
 				return
 			}
@@ -0,0 +1,202 @@
+package flat
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// table stores the buckets of the hashmap. Depending on the storage S the
+// buckets are interleaved in one array (AoS) or the keys and values
+// live in separate arrays (SoA). All accesses to the buckets are done
+// through the table, so the hashmap itself is layout agnostic.
+type table[K comparable, V any, S shared.Storage] struct {
+	// buckets is used by the AoS layout
+	buckets []bucket[K, V]
+	// keys and values are used by the SoA layout
+	keys   []K
+	values []V
+	// cow is set, if snapshots share the buckets, see `preserve`
+	cow *shared.COW[table[K, V, S]]
+	// gens is enabled by `LazyClear`, the key of a stale bucket is `empty`
+	gens  shared.Generations
+	empty K
+}
+
+//go:inline
+func newTable[K comparable, V any, S shared.Storage](capacity uintptr, empty K, lazy bool) table[K, V, S] {
+	t := table[K, V, S]{empty: empty}
+
+	if lazy {
+		t.gens = shared.NewGenerations(capacity)
+	}
+
+	if !shared.IsSoA[S]() {
+		t.buckets = newBucketArray[K, V](capacity, empty)
+		return t
+	}
+
+	t.keys = make([]K, capacity)
+	t.values = make([]V, capacity)
+
+	var zero K
+
+	if zero != empty {
+		// need to "zero" the keys
+		for i := range t.keys {
+			t.keys[i] = empty
+		}
+	}
+
+	return t
+}
+
+// len returns the number of buckets.
+//
+//go:inline
+func (t *table[K, V, S]) len() uintptr {
+	if shared.IsSoA[S]() {
+		return uintptr(len(t.keys))
+	}
+
+	return uintptr(len(t.buckets))
+}
+
+//go:inline
+func (t *table[K, V, S]) key(i uintptr) K {
+	if t.gens.Stale(i) {
+		return t.empty
+	}
+
+	if shared.IsSoA[S]() {
+		return t.keys[i]
+	}
+
+	return t.buckets[i].key
+}
+
+// preserve must be called before the i-th bucket is modified.
+// It copies the page of the bucket for the snapshots, that share it.
+//
+//go:inline
+func (t *table[K, V, S]) preserve(i uintptr) {
+	if t.cow != nil && !t.cow.Preserved(i) {
+		t.cow.Preserve(i, t.len(), t.copyRange)
+	}
+}
+
+//go:inline
+func (t *table[K, V, S]) setKey(i uintptr, key K) {
+	t.preserve(i)
+	t.gens.Stamp(i)
+
+	if shared.IsSoA[S]() {
+		t.keys[i] = key
+	} else {
+		t.buckets[i].key = key
+	}
+}
+
+// value returns a pointer to the value of the i-th bucket.
+//
+//go:inline
+func (t *table[K, V, S]) value(i uintptr) *V {
+	if shared.IsSoA[S]() {
+		return &t.values[i]
+	}
+
+	return &t.buckets[i].value
+}
+
+//go:inline
+func (t *table[K, V, S]) setValue(i uintptr, val V) {
+	t.preserve(i)
+	*t.value(i) = val
+}
+
+// editValue returns a pointer to the value of the i-th bucket,
+// which can be modified without affecting a snapshot.
+//
+//go:inline
+func (t *table[K, V, S]) editValue(i uintptr) *V {
+	t.preserve(i)
+	return t.value(i)
+}
+
+// store overwrites the i-th bucket with the key-value pair.
+//
+//go:inline
+func (t *table[K, V, S]) store(i uintptr, key K, val V) {
+	t.preserve(i)
+	t.gens.Stamp(i)
+
+	if shared.IsSoA[S]() {
+		t.keys[i] = key
+		t.values[i] = val
+	} else {
+		t.buckets[i].key = key
+		t.buckets[i].value = val
+	}
+}
+
+// clear marks all buckets as empty. If snapshots share
+// the buckets, a new table is allocated instead.
+func (t *table[K, V, S]) clear(empty K) {
+	if t.cow != nil {
+		*t = newTable[K, V, S](t.len(), empty, t.gens.Enabled())
+		return
+	}
+
+	if t.gens.Enabled() {
+		t.gens.Next()
+		return
+	}
+
+	if shared.IsSoA[S]() {
+		for i := range t.keys {
+			t.keys[i] = empty
+		}
+	} else {
+		for i := range t.buckets {
+			t.buckets[i].key = empty
+		}
+	}
+}
+
+// lazyClear enables or disables the generation stamps. The stale
+// buckets are marked as empty, before the stamps are dropped.
+func (t *table[K, V, S]) lazyClear(on bool) {
+	switch {
+	case on && !t.gens.Enabled():
+		t.gens = shared.NewGenerations(t.len())
+	case !on && t.gens.Enabled():
+		for i := uintptr(0); i < t.len(); i++ {
+			if t.gens.Stale(i) {
+				t.setKey(i, t.empty)
+			}
+		}
+
+		t.gens = shared.Generations{}
+	}
+}
+
+// copy returns a deep copy of the table.
+func (t *table[K, V, S]) copy() table[K, V, S] {
+	return t.copyRange(0, t.len())
+}
+
+// copyRange returns a deep copy of the buckets in range [lo,hi).
+func (t *table[K, V, S]) copyRange(lo, hi uintptr) table[K, V, S] {
+	if shared.IsSoA[S]() {
+		return table[K, V, S]{
+			keys:   append([]K(nil), t.keys[lo:hi]...),
+			values: append([]V(nil), t.values[lo:hi]...),
+			gens:   t.gens.Copy(lo, hi),
+			empty:  t.empty,
+		}
+	}
+
+	return table[K, V, S]{
+		buckets: append([]bucket[K, V](nil), t.buckets[lo:hi]...),
+		gens:    t.gens.Copy(lo, hi),
+		empty:   t.empty,
+	}
+}
@@ -0,0 +1,185 @@
+// Package hamt implements a persistent hash array mapped trie (HAMT).
+package hamt
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// Map is a persistent hashmap, means it is never modified. Each modification
+// like `With` or `Without` returns a new version of the hashmap, which shares
+// all unchanged nodes with the old version. So a modification costs only
+// O(log32(n)) instead of O(n) for a `Copy` of the other hashmaps.
+// A Map is safe for concurrent use, because it is immutable.
+// Use a `Builder` to construct a hashmap with many modifications.
+type Map[K comparable, V any] struct {
+	root   *node[K, V]
+	hasher shared.HashFn[K]
+	size   int
+}
+
+// New creates an empty `Map` with default settings.
+func New[K comparable, V any]() *Map[K, V] {
+	return NewWithHasher[K, V](shared.GetHasher[K]())
+}
+
+// NewWithHasher same as `New` but with a given hash function.
+func NewWithHasher[K comparable, V any](hasher shared.HashFn[K]) *Map[K, V] {
+	return &Map[K, V]{
+		hasher: hasher,
+	}
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if m.root == nil {
+		var v V
+		return v, false
+	}
+
+	return m.root.get(m.hasher(key), key)
+}
+
+// With returns a new version of the hashmap, that contains the key-value pair.
+// If the key already exists its value will be overwritten with the new value.
+func (m *Map[K, V]) With(key K, val V) *Map[K, V] {
+	root, added := with(m.root, nil, m.hasher(key), key, val)
+
+	res := &Map[K, V]{
+		root:   root,
+		hasher: m.hasher,
+		size:   m.size,
+	}
+
+	if added {
+		res.size++
+	}
+
+	return res
+}
+
+// Without returns a new version of the hashmap, that does not contain the key.
+// If the key is not in, the hashmap itself is returned.
+func (m *Map[K, V]) Without(key K) *Map[K, V] {
+	if m.root == nil {
+		return m
+	}
+
+	root, removed := m.root.without(nil, 0, m.hasher(key), key)
+	if !removed {
+		return m
+	}
+
+	return &Map[K, V]{
+		root:   root,
+		hasher: m.hasher,
+		size:   m.size - 1,
+	}
+}
+
+// Size returns the number of items in the hashmap.
+func (m *Map[K, V]) Size() int {
+	return m.size
+}
+
+// Each calls 'fn' on every key-value pair in the hash map in no particular order.
+// If 'fn' returns true, the iteration stops.
+func (m *Map[K, V]) Each(fn func(key K, val V) bool) {
+	if m.root != nil {
+		m.root.each(fn)
+	}
+}
+
+// Builder returns a transient version of the hashmap, which is modified
+// in place. The hashmap itself stays unchanged.
+func (m *Map[K, V]) Builder() *Builder[K, V] {
+	return &Builder[K, V]{
+		root:   m.root,
+		hasher: m.hasher,
+		size:   m.size,
+		owner:  &owner{},
+	}
+}
+
+// Builder is a transient hashmap to construct a `Map` fast. The nodes, which
+// are created by the builder, are modified in place, instead of copying them.
+// A Builder is not safe for concurrent use.
+type Builder[K comparable, V any] struct {
+	root   *node[K, V]
+	hasher shared.HashFn[K]
+	owner  *owner
+	size   int
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (b *Builder[K, V]) Get(key K) (V, bool) {
+	if b.root == nil {
+		var v V
+		return v, false
+	}
+
+	return b.root.get(b.hasher(key), key)
+}
+
+// Put adds the given key-value pair to the hashmap. If the key already exists its
+// value will be overwritten with the new value.
+// Returns true, if the element is a new item in the hashmap.
+func (b *Builder[K, V]) Put(key K, val V) bool {
+	var added bool
+
+	b.root, added = with(b.root, b.owner, b.hasher(key), key, val)
+	if added {
+		b.size++
+	}
+
+	return added
+}
+
+// Remove removes the specified key-value pair from the hashmap.
+// Returns true, if the element was in the hashmap.
+func (b *Builder[K, V]) Remove(key K) bool {
+	if b.root == nil {
+		return false
+	}
+
+	var removed bool
+
+	b.root, removed = b.root.without(b.owner, 0, b.hasher(key), key)
+	if removed {
+		b.size--
+	}
+
+	return removed
+}
+
+// Size returns the number of items in the hashmap.
+func (b *Builder[K, V]) Size() int {
+	return b.size
+}
+
+// Map returns the persistent version of the hashmap. The builder can be
+// used further on, without changing the returned hashmap.
+func (b *Builder[K, V]) Map() *Map[K, V] {
+	// the nodes are shared from now on
+	b.owner = &owner{}
+
+	return &Map[K, V]{
+		root:   b.root,
+		hasher: b.hasher,
+		size:   b.size,
+	}
+}
+
+//go:inline
+func with[K comparable, V any](root *node[K, V], o *owner, hash uintptr, key K, val V) (*node[K, V], bool) {
+	if root == nil {
+		// the new root is created with the entry, because
+		// `editable` would copy it again for a persistent `With`
+		return &node[K, V]{
+			bitmap: fragment(hash, 0),
+			slots:  []slot[K, V]{{hash: hash, key: key, value: val}},
+			owner:  o,
+		}, true
+	}
+
+	return root.with(o, 0, hash, key, val)
+}
@@ -0,0 +1,270 @@
+package hamt
+
+import (
+	"math/bits"
+)
+
+const (
+	// bitsPerLevel is the number of hash bits, which are consumed per level
+	bitsPerLevel = 5
+	levelMask    = 1<<bitsPerLevel - 1
+	// maxShift is reached, if all bits of the hash value are consumed.
+	// The nodes on this level store the colliding entries in a plain list.
+	maxShift = bits.UintSize
+)
+
+// owner marks the nodes, which are created by a `Builder`. These nodes are
+// not shared yet and can be modified in place. The struct must not be
+// zero sized, otherwise two owners could have the same address.
+type owner struct {
+	_ byte
+}
+
+// slot is either a entry or a pointer to a child node.
+type slot[K comparable, V any] struct {
+	child *node[K, V]
+	hash  uintptr
+	key   K
+	value V
+}
+
+// node is a bitmap indexed node of the trie. The bit i of the bitmap is set,
+// if there is a slot for the hash fragment i. The slots are stored densely,
+// so the position of a slot is the number of set bits below bit i.
+// At `maxShift` the bitmap is unused and the slots are a list of entries.
+type node[K comparable, V any] struct {
+	bitmap uint32
+	slots  []slot[K, V]
+	owner  *owner
+}
+
+//go:inline
+func fragment(hash uintptr, shift uint) uint32 {
+	return 1 << ((hash >> shift) & levelMask)
+}
+
+//go:inline
+func (n *node[K, V]) index(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// editable returns the node itself, if it is owned by `o`, otherwise a copy.
+func (n *node[K, V]) editable(o *owner) *node[K, V] {
+	if o != nil && n.owner == o {
+		return n
+	}
+
+	return &node[K, V]{
+		bitmap: n.bitmap,
+		slots:  append(make([]slot[K, V], 0, len(n.slots)+1), n.slots...),
+		owner:  o,
+	}
+}
+
+func (n *node[K, V]) get(hash uintptr, key K) (V, bool) {
+	for shift := uint(0); shift < maxShift; shift += bitsPerLevel {
+		bit := fragment(hash, shift)
+		if n.bitmap&bit == 0 {
+			var v V
+			return v, false
+		}
+
+		s := &n.slots[n.index(bit)]
+		if s.child == nil {
+			if s.hash == hash && s.key == key {
+				return s.value, true
+			}
+
+			var v V
+
+			return v, false
+		}
+
+		n = s.child
+	}
+
+	for i := range n.slots {
+		if n.slots[i].key == key {
+			return n.slots[i].value, true
+		}
+	}
+
+	var v V
+
+	return v, false
+}
+
+// with returns a node, that contains the entry. The node is only modified
+// in place, if it is owned by `o`. Returns true, if the key was not in.
+func (n *node[K, V]) with(o *owner, shift uint, hash uintptr, key K, val V) (*node[K, V], bool) {
+	if shift >= maxShift {
+		for i := range n.slots {
+			if n.slots[i].key == key {
+				n = n.editable(o)
+				n.slots[i].value = val
+
+				return n, false
+			}
+		}
+
+		n = n.editable(o)
+		n.slots = append(n.slots, slot[K, V]{hash: hash, key: key, value: val})
+
+		return n, true
+	}
+
+	var (
+		bit = fragment(hash, shift)
+		idx = n.index(bit)
+	)
+
+	if n.bitmap&bit == 0 {
+		n = n.editable(o)
+		n.bitmap |= bit
+		n.slots = append(n.slots, slot[K, V]{})
+		copy(n.slots[idx+1:], n.slots[idx:])
+		n.slots[idx] = slot[K, V]{hash: hash, key: key, value: val}
+
+		return n, true
+	}
+
+	s := n.slots[idx]
+
+	switch {
+	case s.child != nil:
+		child, added := s.child.with(o, shift+bitsPerLevel, hash, key, val)
+		if child != s.child {
+			n = n.editable(o)
+			n.slots[idx].child = child
+		}
+
+		return n, added
+	case s.hash == hash && s.key == key:
+		n = n.editable(o)
+		n.slots[idx].value = val
+
+		return n, false
+	default:
+		// split both entries into a new child node
+		child := split(o, shift+bitsPerLevel, s, slot[K, V]{hash: hash, key: key, value: val})
+
+		n = n.editable(o)
+		n.slots[idx] = slot[K, V]{child: child}
+
+		return n, true
+	}
+}
+
+// split creates a subtrie, that contains both entries.
+func split[K comparable, V any](o *owner, shift uint, a, b slot[K, V]) *node[K, V] {
+	if shift >= maxShift {
+		return &node[K, V]{slots: []slot[K, V]{a, b}, owner: o}
+	}
+
+	var (
+		bitA = fragment(a.hash, shift)
+		bitB = fragment(b.hash, shift)
+	)
+
+	switch {
+	case bitA == bitB:
+		child := split(o, shift+bitsPerLevel, a, b)
+		return &node[K, V]{bitmap: bitA, slots: []slot[K, V]{{child: child}}, owner: o}
+	case bitA < bitB:
+		return &node[K, V]{bitmap: bitA | bitB, slots: []slot[K, V]{a, b}, owner: o}
+	default:
+		return &node[K, V]{bitmap: bitA | bitB, slots: []slot[K, V]{b, a}, owner: o}
+	}
+}
+
+// without returns a node, that does not contain the key or nil if the node
+// becomes empty. The node is only modified in place, if it is owned by `o`.
+// Returns true, if the key was in.
+func (n *node[K, V]) without(o *owner, shift uint, hash uintptr, key K) (*node[K, V], bool) {
+	if shift >= maxShift {
+		for i := range n.slots {
+			if n.slots[i].key == key {
+				return n.removeSlot(o, i), true
+			}
+		}
+
+		return n, false
+	}
+
+	var (
+		bit = fragment(hash, shift)
+		idx = n.index(bit)
+	)
+
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	s := n.slots[idx]
+
+	if s.child == nil {
+		if s.hash != hash || s.key != key {
+			return n, false
+		}
+
+		n = n.removeSlot(o, idx)
+		if n != nil {
+			n.bitmap &^= bit
+		}
+
+		return n, true
+	}
+
+	child, removed := s.child.without(o, shift+bitsPerLevel, hash, key)
+	if !removed {
+		return n, false
+	}
+
+	switch {
+	case child == nil:
+		n = n.removeSlot(o, idx)
+		if n != nil {
+			n.bitmap &^= bit
+		}
+	case len(child.slots) == 1 && child.slots[0].child == nil:
+		// pull up the last entry of the child node
+		n = n.editable(o)
+		n.slots[idx] = child.slots[0]
+	default:
+		n = n.editable(o)
+		n.slots[idx].child = child
+	}
+
+	return n, true
+}
+
+// removeSlot removes the slot at index `i` or returns nil,
+// if it was the last one.
+func (n *node[K, V]) removeSlot(o *owner, i int) *node[K, V] {
+	if len(n.slots) == 1 {
+		return nil
+	}
+
+	n = n.editable(o)
+	copy(n.slots[i:], n.slots[i+1:])
+	n.slots[len(n.slots)-1] = slot[K, V]{}
+	n.slots = n.slots[:len(n.slots)-1]
+
+	return n
+}
+
+// each calls 'fn' on every entry. Returns true, if 'fn' stops the iteration.
+func (n *node[K, V]) each(fn func(key K, val V) bool) bool {
+	for i := range n.slots {
+		s := &n.slots[i]
+		if s.child != nil {
+			if s.child.each(fn) {
+				return true
+			}
+		} else if fn(s.key, s.value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,176 @@
+package hashcheck
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// The limits are multiples of the standard deviation of a random hash function,
+// so a good hash function passes the checks independent of the sample size.
+const (
+	avalancheSigmas = 5
+	bicSigmas       = 6
+	chiSquaredLimit = 6
+	collisionSigmas = 5
+)
+
+// result is the outcome of one check.
+type result struct {
+	hasher string
+	check  string
+	corpus string
+	// score is compared to the limit, lower is better
+	score float64
+	limit float64
+}
+
+func (r result) pass() bool {
+	return r.score <= r.limit
+}
+
+// avalanche flips every input bit of random keys and measures the probability,
+// that an output bit flips. Ideally each output bit flips with probability 0.5
+// (strict avalanche criterion), the score is the maximum bias from 0.5.
+// Furthermore the bit independence criterion (BIC) is checked, where the flips
+// of two output bits should be uncorrelated, the score is the maximum correlation.
+// Only the lower `outBits` are checked, because the hashmaps use `hash & capMinus1`.
+func avalanche[K comparable](s *Subject[K], rng *rand.Rand, samples, outBits int) (sac, bic result) {
+	var (
+		flips = make([][]int, s.Bits)
+		// pairs[i][j*outBits+k] counts the common flips of the output bits j and k
+		pairs = make([][]int, s.Bits)
+		mask  = uint64(1)<<outBits - 1
+	)
+
+	if outBits >= 64 {
+		mask = ^uint64(0)
+	}
+
+	for i := range flips {
+		flips[i] = make([]int, outBits)
+		pairs[i] = make([]int, outBits*outBits)
+	}
+
+	for n := 0; n < samples; n++ {
+		key := s.Random(rng)
+		h := uint64(s.Hash(key))
+
+		for i := 0; i < s.Bits; i++ {
+			diff := (uint64(s.Hash(s.Flip(key, i))) ^ h) & mask
+
+			for d := diff; d != 0; d &= d - 1 {
+				j := bits.TrailingZeros64(d)
+				flips[i][j]++
+
+				for e := d & (d - 1); e != 0; e &= e - 1 {
+					pairs[i][j*outBits+bits.TrailingZeros64(e)]++
+				}
+			}
+		}
+	}
+
+	var (
+		total    = float64(samples)
+		maxBias  float64
+		maxCorr  float64
+		binomial = 0.5 / math.Sqrt(total)
+	)
+
+	for i := 0; i < s.Bits; i++ {
+		for j := 0; j < outBits; j++ {
+			pj := float64(flips[i][j]) / total
+			maxBias = math.Max(maxBias, math.Abs(pj-0.5))
+
+			for k := j + 1; k < outBits; k++ {
+				pk := float64(flips[i][k]) / total
+				maxCorr = math.Max(maxCorr, math.Abs(correlation(pj, pk, float64(pairs[i][j*outBits+k])/total)))
+			}
+		}
+	}
+
+	sac = result{check: "avalanche", score: maxBias, limit: avalancheSigmas * binomial}
+	bic = result{check: "bit-independence", score: maxCorr, limit: bicSigmas / math.Sqrt(total)}
+
+	return sac, bic
+}
+
+// correlation returns the correlation of two indicator variables with the
+// probabilities pj, pk and the probability pjk, that both are set.
+// A constant variable is treated as fully correlated.
+func correlation(pj, pk, pjk float64) float64 {
+	v := pj * (1 - pj) * pk * (1 - pk)
+	if v == 0 {
+		return 1
+	}
+
+	return (pjk - pj*pk) / math.Sqrt(v)
+}
+
+// chiSquared distributes the keys into tables of power of two sizes by
+// `hash & (size-1)` and computes the chi-squared statistic of the bucket
+// counts. The score is the worst normalized statistic (chi2-df)/sqrt(2df),
+// which is approximately standard normal distributed for a random hash function.
+func chiSquared[K comparable](s *Subject[K], keys []K) result {
+	var (
+		hashes = make([]uint64, len(keys))
+		worst  = math.Inf(-1)
+	)
+
+	for i, k := range keys {
+		hashes[i] = uint64(s.Hash(k))
+	}
+
+	for size := 16; size <= len(keys); size *= 2 {
+		var (
+			counts   = make([]int, size)
+			mask     = uint64(size - 1)
+			expected = float64(len(keys)) / float64(size)
+			chi2     float64
+		)
+
+		for _, h := range hashes {
+			counts[h&mask]++
+		}
+
+		for _, c := range counts {
+			d := float64(c) - expected
+			chi2 += d * d / expected
+		}
+
+		df := float64(size - 1)
+		worst = math.Max(worst, (chi2-df)/math.Sqrt(2*df))
+	}
+
+	return result{check: "chi-squared", score: worst, limit: chiSquaredLimit}
+}
+
+// collisions counts the keys, which have the same full hash value as a
+// previous key. The limit is derived from the birthday problem.
+func collisions[K comparable](s *Subject[K], keys []K) result {
+	var (
+		seen  = make(map[uint64]struct{}, len(keys))
+		count int
+	)
+
+	for _, k := range keys {
+		h := uint64(s.Hash(k))
+
+		if _, ok := seen[h]; ok {
+			count++
+		}
+
+		seen[h] = struct{}{}
+	}
+
+	var (
+		n        = float64(len(keys))
+		expected = n * (n - 1) / 2 / math.Exp2(float64(s.OutBits))
+	)
+
+	return result{
+		check: "collisions",
+		score: float64(count),
+		limit: math.Floor(expected + collisionSigmas*math.Sqrt(expected)),
+	}
+}
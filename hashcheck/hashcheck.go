@@ -0,0 +1,135 @@
+// Package hashcheck evaluates the quality of the hash functions, that are
+// returned by `shared.GetHasher` and `shared.GetSeededHasher`, and of the
+// custom hashers added by `Register`. Each hasher is checked by:
+//
+//   - avalanche: every input bit flips each output bit with probability 0.5
+//   - bit-independence: the flips of two output bits are uncorrelated
+//   - chi-squared: the keys of a corpus are distributed uniformly over the
+//     buckets of power of two sized tables, because all hashmaps of this
+//     module use `hash & capMinus1`
+//   - collisions: the number of equal hash values of a corpus is not higher
+//     than expected by the birthday problem
+//
+// Usage:
+//
+//	func init() {
+//		hashcheck.Register(hashcheck.Uint64Subject("my/uint64", myHasher))
+//	}
+//
+//	err := hashcheck.Run(os.Stdout, hashcheck.Config{Hashers: []string{"my/uint64"}})
+package hashcheck
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+const (
+	// DefaultN is the default maximum number of keys per corpus.
+	DefaultN = 100000
+	// DefaultSamples is the default number of random keys of the avalanche checks.
+	DefaultSamples = 10000
+	// DefaultBits is the default number of lower output bits of the avalanche checks.
+	DefaultBits = 32
+)
+
+// Config configures the checks of `Run`.
+type Config struct {
+	// N is the maximum number of keys per corpus, at least 16.
+	// If unset `DefaultN` is used.
+	N int
+	// Samples is the number of random keys of the avalanche checks.
+	// If unset `DefaultSamples` is used.
+	Samples int
+	// Bits is the number of lower output bits of the avalanche checks, at most 64.
+	// If unset `DefaultBits` is used.
+	Bits int
+	// Seed of the random keys.
+	Seed int64
+	// Hashers are the names of the checked hashers, all registered if empty.
+	Hashers []string
+}
+
+// Run checks the selected hashers and writes a report with one line per
+// check to `out`. Returns ErrOutOfRange for an invalid `Config`,
+// ErrUnsupported for an unknown hasher or an error, if a check fails.
+func Run(out io.Writer, cfg Config) error {
+	cfg.N = defaultOf(cfg.N, DefaultN)
+	cfg.Samples = defaultOf(cfg.Samples, DefaultSamples)
+	cfg.Bits = defaultOf(cfg.Bits, DefaultBits)
+
+	if cfg.N < 16 || cfg.Samples <= 0 || cfg.Bits <= 0 || cfg.Bits > 64 {
+		return fmt.Errorf("n %d, samples %d, bits %d: %w", cfg.N, cfg.Samples, cfg.Bits, shared.ErrOutOfRange)
+	}
+
+	selected := registry
+
+	if len(cfg.Hashers) > 0 {
+		selected = nil
+
+		for _, name := range cfg.Hashers {
+			c := lookup(name)
+			if c == nil {
+				return fmt.Errorf("hasher %q: %w", name, shared.ErrUnsupported)
+			}
+
+			selected = append(selected, c)
+		}
+	}
+
+	var (
+		w      = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+		failed int
+		total  int
+	)
+
+	fmt.Fprintln(w, "hasher\tcheck\tcorpus\tscore\tlimit\tstatus\t")
+
+	for _, c := range selected {
+		for _, r := range c.run(cfg) {
+			status := "ok"
+			if !r.pass() {
+				status = "FAIL"
+				failed++
+			}
+
+			total++
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", r.hasher, r.check, r.corpus,
+				strconv.FormatFloat(r.score, 'g', 4, 64), strconv.FormatFloat(r.limit, 'g', 4, 64), status)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, total)
+	}
+
+	return nil
+}
+
+//go:inline
+func defaultOf(v, def int) int {
+	if v == 0 {
+		return def
+	}
+
+	return v
+}
+
+func lookup(name string) checker {
+	for _, c := range registry {
+		if c.hasherName() == name {
+			return c
+		}
+	}
+
+	return nil
+}
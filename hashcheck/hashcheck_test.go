@@ -0,0 +1,48 @@
+package hashcheck_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EinfachAndy/hashmaps/hashcheck"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+func init() {
+	// the identity is a poor hasher, that does not mix the bits
+	hashcheck.Register(hashcheck.Uint64Subject("identity", func(k uint64) uintptr { return uintptr(k) }))
+
+	seeded, err := shared.GetSeededHasher[string](shared.WyHash, 42)
+	if err != nil {
+		panic(err)
+	}
+
+	hashcheck.Register(hashcheck.StringSubject("seeded/string", seeded))
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+
+	cfg := hashcheck.Config{N: 5000, Samples: 2000, Hashers: []string{"seeded/string", "wyhash/uint64"}}
+	assert.NoError(t, hashcheck.Run(&out, cfg))
+	assert.Regexp(t, `seeded/string\s+avalanche\s+random\s+\S+\s+\S+\s+ok`, out.String())
+	assert.NotContains(t, out.String(), "FAIL")
+
+	out.Reset()
+
+	cfg.Hashers = []string{"identity"}
+	assert.Error(t, hashcheck.Run(&out, cfg))
+	assert.Regexp(t, `identity\s+avalanche\s+random\s+\S+\s+\S+\s+FAIL`, out.String())
+
+	assert.ErrorIs(t, hashcheck.Run(&out, hashcheck.Config{Hashers: []string{"complex64"}}), shared.ErrUnsupported)
+
+	for _, cfg := range []hashcheck.Config{
+		{N: 8},
+		{Bits: 65},
+		{Samples: -1},
+	} {
+		assert.ErrorIs(t, hashcheck.Run(&out, cfg), shared.ErrOutOfRange, cfg)
+	}
+}
@@ -0,0 +1,296 @@
+package hashcheck
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"strconv"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// Subject is a hasher under test with the key specific helpers.
+// All fields must be set.
+type Subject[K comparable] struct {
+	Name string
+	Hash shared.HashFn[K]
+	// Bits is the number of input bits, which are flipped by the avalanche checks
+	Bits int
+	// OutBits is the number of significant bits of the hash values
+	OutBits int
+	// Flip returns the key with the i-th bit flipped
+	Flip func(key K, i int) K
+	// Random returns a random key
+	Random  func(rng *rand.Rand) K
+	Corpora []Corpus[K]
+}
+
+// Corpus is a realistic set of unique keys.
+type Corpus[K comparable] struct {
+	Name string
+	// Keys returns up to n keys
+	Keys func(n int) []K
+}
+
+// checker runs all checks of one hasher.
+type checker interface {
+	hasherName() string
+	run(cfg Config) []result
+}
+
+func (s *Subject[K]) hasherName() string {
+	return s.Name
+}
+
+func (s *Subject[K]) run(cfg Config) []result {
+	var (
+		rng      = rand.New(rand.NewSource(cfg.Seed))
+		sac, bic = avalanche(s, rng, cfg.Samples, min(cfg.Bits, s.OutBits))
+		res      = []result{sac, bic}
+	)
+
+	res[0].corpus, res[1].corpus = "random", "random"
+
+	for _, c := range s.Corpora {
+		keys := c.Keys(cfg.N)
+
+		for _, r := range []result{chiSquared(s, keys), collisions(s, keys)} {
+			r.corpus = c.Name
+			res = append(res, r)
+		}
+	}
+
+	for i := range res {
+		res[i].hasher = s.Name
+	}
+
+	return res
+}
+
+// registry holds the hashers, which are checked.
+var registry []checker
+
+// Register adds a custom hasher to the checks of `Run`, where the hash values
+// are limited to the width of uintptr. It is not safe to call `Register`
+// concurrently with `Run`, so hashers are usually registered by init functions.
+func Register[K comparable](s *Subject[K]) {
+	s.OutBits = min(s.OutBits, bits.UintSize)
+	registry = append(registry, s)
+}
+
+func init() {
+	Register(&Subject[uint8]{
+		Name: "uint8", Hash: shared.GetHasher[uint8](), Bits: 8, OutBits: 32,
+		Flip:    func(k uint8, i int) uint8 { return k ^ 1<<i },
+		Random:  func(rng *rand.Rand) uint8 { return uint8(rng.Uint32()) },
+		Corpora: []Corpus[uint8]{{"all", sequential[uint8](math.MaxUint8 + 1)}},
+	})
+
+	Register(&Subject[uint16]{
+		Name: "uint16", Hash: shared.GetHasher[uint16](), Bits: 16, OutBits: 32,
+		Flip:    func(k uint16, i int) uint16 { return k ^ 1<<i },
+		Random:  func(rng *rand.Rand) uint16 { return uint16(rng.Uint32()) },
+		Corpora: []Corpus[uint16]{{"sequential", sequential[uint16](math.MaxUint16 + 1)}},
+	})
+
+	Register(&Subject[uint32]{
+		Name: "uint32", Hash: shared.GetHasher[uint32](), Bits: 32, OutBits: 32,
+		Flip:   func(k uint32, i int) uint32 { return k ^ 1<<i },
+		Random: func(rng *rand.Rand) uint32 { return rng.Uint32() },
+		Corpora: []Corpus[uint32]{
+			{"sequential", sequential[uint32](math.MaxUint32)},
+			{"strided", strided[uint32](8, 32)},
+		},
+	})
+
+	Register(Uint64Subject("uint64", shared.GetHasher[uint64]()))
+
+	Register(&Subject[float32]{
+		Name: "float32", Hash: shared.GetHasher[float32](), Bits: 32, OutBits: 32,
+		Flip: func(k float32, i int) float32 {
+			return math.Float32frombits(math.Float32bits(k) ^ 1<<i)
+		},
+		Random: func(rng *rand.Rand) float32 { return math.Float32frombits(rng.Uint32()) },
+		Corpora: []Corpus[float32]{
+			{"integral", floats(func(i int) float32 { return float32(i) })},
+			{"fractions", floats(func(i int) float32 { return float32(i) / 1024 })},
+		},
+	})
+
+	Register(&Subject[float64]{
+		Name: "float64", Hash: shared.GetHasher[float64](), Bits: 64, OutBits: 64,
+		Flip: func(k float64, i int) float64 {
+			return math.Float64frombits(math.Float64bits(k) ^ 1<<i)
+		},
+		Random: func(rng *rand.Rand) float64 { return math.Float64frombits(rng.Uint64()) },
+		Corpora: []Corpus[float64]{
+			{"integral", floats(func(i int) float64 { return float64(i) })},
+			{"fractions", floats(func(i int) float64 { return float64(i) / 1024 })},
+		},
+	})
+
+	Register(StringSubject("string", shared.GetHasher[string]()))
+
+	// the seeded algorithms are checked for the keys of 64 bits and strings
+	for _, a := range shared.Algorithms() {
+		if a == shared.Default {
+			continue
+		}
+
+		var (
+			u, _   = shared.GetSeededHasher[uint64](a, 0)
+			str, _ = shared.GetSeededHasher[string](a, 0)
+			us     = Uint64Subject(a.String()+"/uint64", u)
+			ss     = StringSubject(a.String()+"/string", str)
+		)
+
+		if a == shared.CRC32C {
+			// the checksum has 32 bits
+			us.OutBits, ss.OutBits = 32, 32
+		}
+
+		Register(us)
+		Register(ss)
+	}
+}
+
+// Uint64Subject returns a `Subject` for a hasher of uint64 keys, which is
+// checked by sequential and strided keys.
+func Uint64Subject(name string, hash shared.HashFn[uint64]) *Subject[uint64] {
+	return &Subject[uint64]{
+		Name: name, Hash: hash, Bits: 64, OutBits: 64,
+		Flip:   func(k uint64, i int) uint64 { return k ^ 1<<i },
+		Random: func(rng *rand.Rand) uint64 { return rng.Uint64() },
+		Corpora: []Corpus[uint64]{
+			{"sequential", sequential[uint64](math.MaxInt)},
+			{"strided", strided[uint64](32, 64)},
+		},
+	}
+}
+
+// StringSubject returns a `Subject` for a hasher of strings, which is checked
+// by random strings of 15 bytes, short strings, numbers and words.
+func StringSubject(name string, hash shared.HashFn[string]) *Subject[string] {
+	// the random strings of 15 bytes cover all paths of the string hashers,
+	// e.g.: a word of 8 bytes, a tail of 4, 2 and 1 bytes
+	const strLen = 15
+
+	return &Subject[string]{
+		Name: name, Hash: hash, Bits: 8 * strLen, OutBits: 64,
+		Flip: func(k string, i int) string {
+			b := []byte(k)
+			b[i/8] ^= 1 << (i % 8)
+
+			return string(b)
+		},
+		Random: func(rng *rand.Rand) string {
+			b := make([]byte, strLen)
+			rng.Read(b)
+
+			return string(b)
+		},
+		Corpora: []Corpus[string]{
+			{"short", shortStrings},
+			{"decimal", formatted("")},
+			{"prefixed", formatted("user:")},
+			{"words", words},
+		},
+	}
+}
+
+type unsigned interface {
+	~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// sequential returns the keys 0, 1, 2... up to `limit` keys.
+func sequential[K unsigned](limit uint64) func(n int) []K {
+	return func(n int) []K {
+		keys := make([]K, min(uint64(n), limit))
+		for i := range keys {
+			keys[i] = K(i)
+		}
+
+		return keys
+	}
+}
+
+// strided returns the keys 0, 1<<shift, 2<<shift... of a type with `width` bits.
+func strided[K unsigned](shift, width int) func(n int) []K {
+	return func(n int) []K {
+		keys := make([]K, min(uint64(n), uint64(1)<<(width-shift)))
+		for i := range keys {
+			keys[i] = K(i) << shift
+		}
+
+		return keys
+	}
+}
+
+func floats[K float32 | float64](fn func(i int) K) func(n int) []K {
+	return func(n int) []K {
+		keys := make([]K, n)
+		for i := range keys {
+			keys[i] = fn(i)
+		}
+
+		return keys
+	}
+}
+
+// shortStrings returns all strings of 1 to 3 lower case letters.
+func shortStrings(n int) []string {
+	var (
+		keys     []string
+		prefixes = []string{""}
+	)
+
+	for length := 1; length <= 3; length++ {
+		var next []string
+
+		for _, prefix := range prefixes {
+			for c := byte('a'); c <= 'z'; c++ {
+				next = append(next, prefix+string(c))
+			}
+		}
+
+		keys = append(keys, next...)
+		prefixes = next
+	}
+
+	return keys[:min(n, len(keys))]
+}
+
+// formatted returns the decimal numbers with the given prefix.
+func formatted(prefix string) func(n int) []string {
+	return func(n int) []string {
+		keys := make([]string, n)
+		for i := range keys {
+			keys[i] = prefix + strconv.Itoa(i)
+		}
+
+		return keys
+	}
+}
+
+// words returns unique random lower case words of 4 to 12 letters.
+func words(n int) []string {
+	var (
+		rng  = rand.New(rand.NewSource(1))
+		seen = make(map[string]struct{}, n)
+		keys = make([]string, 0, n)
+	)
+
+	for len(keys) < n {
+		b := make([]byte, 4+rng.Intn(9))
+		for i := range b {
+			b[i] = byte('a' + rng.Intn(26))
+		}
+
+		if _, ok := seen[string(b)]; !ok {
+			seen[string(b)] = struct{}{}
+			keys = append(keys, string(b))
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,50 @@
+package hashmaptest
+
+import (
+	"testing"
+)
+
+// Fuzz registers a fuzz target, which interprets the input as program of
+// hashmap operations. The first byte selects the hasher, the following pairs
+// of bytes are the operation and the key index. The result is compared with
+// the golang map like in `Run`.
+//
+// Usage:
+//
+//	func FuzzMyMap(f *testing.F) {
+//		hashmaptest.Fuzz(f, hashmaptest.Config[uint64, int]{...})
+//	}
+func Fuzz[K comparable, V any](f *testing.F, cfg Config[K, V]) {
+	f.Helper()
+
+	if cfg.New == nil || cfg.Key == nil || cfg.Value == nil {
+		f.Fatal("hashmaptest: Config.New, Config.Key and Config.Value must be set")
+	}
+
+	f.Add([]byte{0, 1, 1, 1, 2, 0, 1, 3, 1, 0, 2})
+	f.Add([]byte{1, 1, 0, 1, 1, 1, 2, 2, 3, 3, 0, 1, 4})
+	f.Add([]byte{2, 1, 0, 2, 8, 1, 16, 1, 24, 3, 8, 0, 16})
+
+	f.Fuzz(func(t *testing.T, program []byte) {
+		if len(program) == 0 {
+			return
+		}
+
+		var (
+			c       = cfg.withDefaults(t)
+			hashers = c.hashers()
+			h       = hashers[int(program[0])%len(hashers)]
+			m       = newModel(t, c, h.hasher)
+		)
+
+		for i := 1; i+1 < len(program); i += 2 {
+			m.step(int(program[i]%4), int(program[i+1])%h.domain, i)
+
+			if i%64 == 1 {
+				m.verify()
+			}
+		}
+
+		m.verify()
+	})
+}
@@ -0,0 +1,134 @@
+package hashmaptest
+
+import (
+	"testing"
+
+	"github.com/EinfachAndy/hashmaps"
+	"github.com/EinfachAndy/hashmaps/custom"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// builtinTypes are the hashmap types, which are checked by `RunHasher`.
+var builtinTypes = []hashmaps.Type{
+	hashmaps.Hopscotch,
+	hashmaps.Robin,
+	hashmaps.Unordered,
+	hashmaps.Flat,
+	hashmaps.IndexMap,
+}
+
+// Constant returns a hasher, which maps all keys to the same hash value.
+func Constant[K comparable]() shared.HashFn[K] {
+	return func(K) uintptr {
+		return 0
+	}
+}
+
+// LowEntropy returns a hasher, which keeps only the lower 'bits' of the hash values.
+func LowEntropy[K comparable](hasher shared.HashFn[K], bits int) shared.HashFn[K] {
+	mask := uintptr(1)<<bits - 1
+
+	return func(key K) uintptr {
+		return hasher(key) & mask
+	}
+}
+
+// RunHasher checks, that the hasher is deterministic for the first 'n' keys and
+// runs the tests of `Run` with the hasher for all built-in hashmap types.
+// The distribution of the hash values is evaluated by the hashcheck command.
+func RunHasher[K comparable](t *testing.T, hasher shared.HashFn[K], key func(i int) K, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		k := key(i)
+
+		if h1, h2 := hasher(k), hasher(key(i)); h1 != h2 {
+			t.Fatalf("hash of key %v is not deterministic: %x != %x", k, h1, h2)
+		}
+	}
+
+	for _, typ := range builtinTypes {
+		t.Run(typ.String(), func(t *testing.T) {
+			Run(t, Config[K, int]{
+				New:    Builtin[K, int](typ),
+				Copy:   hashmaps.Copy[K, int],
+				Hasher: hasher,
+				Key:    key,
+				Value:  func(i int) int { return i },
+				Ops:    n,
+			})
+		})
+	}
+}
+
+// RunEqual checks the hasher and the equality of a `custom.Map` for all pairs of
+// 'keys', which should contain equal keys in different representations: each key
+// is equal to itself, the equality is symmetric and equal keys have the same hash
+// value. Afterwards the keys are put, looked up and removed in debug mode.
+// The runtime is quadratic in the number of keys.
+func RunEqual[K any](t *testing.T, hasher shared.HashFn[K], equal custom.EqualFn[K], keys []K) {
+	t.Helper()
+
+	// last stores for each key the index of the last equal key
+	last := make([]int, len(keys))
+	classes := 0
+
+	for i, a := range keys {
+		if !equal(a, a) {
+			t.Fatalf("key %v is not equal to itself", a)
+		}
+
+		last[i] = i
+
+		for j, b := range keys {
+			eq := equal(a, b)
+
+			if eq != equal(b, a) {
+				t.Fatalf("equality of keys %v and %v is not symmetric", a, b)
+			}
+
+			if eq && hasher(a) != hasher(b) {
+				t.Fatalf("keys %v and %v are equal, but have the hash values %x != %x", a, b, hasher(a), hasher(b))
+			}
+
+			if eq && j > last[i] {
+				last[i] = j
+			}
+		}
+
+		if last[i] == i {
+			classes++
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatal(r)
+		}
+	}()
+
+	m := custom.New[K, int](hasher, equal)
+	m.Debug(true)
+
+	for i, key := range keys {
+		m.Put(key, i)
+	}
+
+	if m.Size() != classes {
+		t.Fatalf("size mismatch: %d != %d", m.Size(), classes)
+	}
+
+	for i, key := range keys {
+		if v, found := m.Get(key); !found || v != last[i] {
+			t.Fatalf("key %v: got (%d,%v), want (%d,true)", key, v, found, last[i])
+		}
+	}
+
+	for _, key := range keys {
+		m.Remove(key)
+	}
+
+	if m.Size() != 0 {
+		t.Fatalf("size after remove of all keys: %d", m.Size())
+	}
+}
@@ -0,0 +1,254 @@
+// Package hashmaptest implements a conformance test harness for hashmaps and
+// hashers. A hashmap is checked by a randomized model based test against the
+// golang map, where the operations are compared one by one. Furthermore the
+// behavior at the resize boundaries, the isolation of copies and the early
+// termination of the iteration are tested.
+//
+// Usage:
+//
+//	func TestMyMap(t *testing.T) {
+//		hashmaptest.Run(t, hashmaptest.Config[uint64, int]{
+//			New:   func(h shared.HashFn[uint64]) hashmaps.Map[uint64, int] { return NewMyMap(h) },
+//			Key:   func(i int) uint64 { return uint64(i) + 1 },
+//			Value: func(i int) int { return i },
+//		})
+//	}
+package hashmaptest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/EinfachAndy/hashmaps"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+const (
+	// DefaultOps is the default number of operations of the model based test.
+	DefaultOps = 10000
+	// DefaultCollisions is the default number of keys, which are used with the
+	// colliding hashers. It is bounded by the neighborhood and the overflow list
+	// of the hopscotch hashmap and the 8 bit probe sequence length of the robin hood hashmap.
+	DefaultCollisions = 32
+	// LowEntropyBits is the number of hash bits of the low entropy hasher.
+	LowEntropyBits = 3
+)
+
+// Config describes the hashmap under test.
+type Config[K comparable, V any] struct {
+	// New returns an empty hashmap, which uses the given hasher.
+	New func(hasher shared.HashFn[K]) hashmaps.Map[K, V]
+	// Copy returns an independent copy of the hashmap.
+	// The copy test is skipped, if unset, see `hashmaps.Copy` for the built-in types.
+	Copy func(m hashmaps.Map[K, V]) hashmaps.Map[K, V]
+	// Hasher is the hasher of the model based test without collisions.
+	// If unset `shared.GetHasher` is used, so it must be set for complex key types.
+	Hasher shared.HashFn[K]
+	// Key returns the i-th key of the test domain. Different i must result
+	// in different keys, e.g.: the empty key of the flat hashmap must be skipped.
+	Key func(i int) K
+	// Value returns the i-th value.
+	Value func(i int) V
+	// Ops is the number of operations of the model based test.
+	// If unset `DefaultOps` is used.
+	Ops int
+	// Collisions is the number of keys with the colliding hashers.
+	// If unset `DefaultCollisions` is used.
+	Collisions int
+	// Seed of the random operations.
+	Seed int64
+}
+
+// Builtin returns the factory of a built-in hashmap type for `Config.New`.
+func Builtin[K comparable, V any](typ hashmaps.Type, opts ...hashmaps.Option) func(hasher shared.HashFn[K]) hashmaps.Map[K, V] {
+	return func(hasher shared.HashFn[K]) hashmaps.Map[K, V] {
+		return hashmaps.MustNewHashMap(hashmaps.Config[K, V]{Type: typ, Hasher: hasher}, opts...)
+	}
+}
+
+// Run executes all tests of the harness as sub tests of 't'.
+func Run[K comparable, V any](t *testing.T, cfg Config[K, V]) {
+	t.Helper()
+
+	cfg = cfg.withDefaults(t)
+
+	t.Run("Model", func(t *testing.T) {
+		for _, h := range cfg.hashers() {
+			t.Run(h.name, func(t *testing.T) {
+				rng := rand.New(rand.NewSource(cfg.Seed))
+				m := newModel(t, cfg, h.hasher)
+
+				for i := 0; i < cfg.Ops; i++ {
+					m.step(rng.Intn(4), rng.Intn(h.domain), i)
+
+					if i%100 == 0 {
+						m.verify()
+					}
+				}
+
+				m.verify()
+			})
+		}
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		testResize(t, cfg)
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		if cfg.Copy == nil {
+			t.Skip("Config.Copy is unset")
+		}
+
+		testCopy(t, cfg)
+	})
+
+	t.Run("Each", func(t *testing.T) {
+		testEach(t, cfg)
+	})
+}
+
+func (cfg Config[K, V]) withDefaults(t *testing.T) Config[K, V] {
+	t.Helper()
+
+	if cfg.New == nil || cfg.Key == nil || cfg.Value == nil {
+		t.Fatal("hashmaptest: Config.New, Config.Key and Config.Value must be set")
+	}
+
+	if cfg.Hasher == nil {
+		cfg.Hasher = shared.GetHasher[K]()
+	}
+
+	if cfg.Ops <= 0 {
+		cfg.Ops = DefaultOps
+	}
+
+	if cfg.Collisions <= 0 {
+		cfg.Collisions = DefaultCollisions
+	}
+
+	return cfg
+}
+
+// namedHasher is a hasher of the model based test with the size of its key domain.
+type namedHasher[K comparable] struct {
+	name   string
+	hasher shared.HashFn[K]
+	domain int
+}
+
+func (cfg Config[K, V]) hashers() []namedHasher[K] {
+	return []namedHasher[K]{
+		{"default", cfg.Hasher, max(cfg.Ops/10, 1)},
+		{"constant", Constant[K](), cfg.Collisions},
+		{"low-entropy", LowEntropy(cfg.Hasher, LowEntropyBits), cfg.Collisions},
+	}
+}
+
+// testResize fills the hashmaps up to the power of two boundaries,
+// with and without reserving the buckets before.
+func testResize[K comparable, V any](t *testing.T, cfg Config[K, V]) {
+	for p := 1; p <= 1024; p *= 2 {
+		for _, n := range []int{p - 1, p, p + 1} {
+			for _, reserve := range []bool{false, true} {
+				m := newModel(t, cfg, cfg.Hasher)
+				if reserve {
+					m.m.Reserve(uintptr(n))
+				}
+
+				for i := 0; i < n; i++ {
+					m.put(i, i)
+				}
+
+				m.verify()
+
+				for i := 0; i < n; i += 2 {
+					m.remove(i)
+				}
+
+				m.verify()
+
+				for i := 0; i < n; i++ {
+					m.put(i, n+i)
+				}
+
+				m.verify()
+				m.clear()
+				m.verify()
+			}
+		}
+	}
+}
+
+// testCopy checks, that the modifications of a copy and the
+// original hashmap do not affect each other.
+func testCopy[K comparable, V any](t *testing.T, cfg Config[K, V]) {
+	const n = 100
+
+	orig := newModel(t, cfg, cfg.Hasher)
+	for i := 0; i < n; i++ {
+		orig.put(i, i)
+	}
+
+	cpy := orig.copy()
+	cpy.verify()
+
+	for i := 0; i < n; i++ {
+		switch i % 3 {
+		case 0:
+			cpy.remove(i)
+		case 1:
+			cpy.put(i, n+i)
+		}
+
+		cpy.put(n+i, i)
+	}
+
+	cpy.verify()
+	orig.verify()
+
+	orig.clear()
+	orig.verify()
+	cpy.verify()
+}
+
+// testEach checks the early termination of the iteration.
+func testEach[K comparable, V any](t *testing.T, cfg Config[K, V]) {
+	const n = 50
+
+	m := cfg.New(cfg.Hasher)
+
+	m.Each(func(key K, val V) bool {
+		t.Fatalf("Each called on empty hashmap for key %v", key)
+		return false
+	})
+
+	for i := 0; i < n; i++ {
+		m.Put(cfg.Key(i), cfg.Value(i))
+	}
+
+	for stop := 1; stop <= n; stop++ {
+		count := 0
+
+		m.Each(func(key K, val V) bool {
+			count++
+			return count == stop
+		})
+
+		if count != stop {
+			t.Fatalf("Each called %d times, expected a stop after %d", count, stop)
+		}
+
+		count = 0
+
+		for range hashmaps.All[K, V](m) {
+			if count++; count == stop {
+				break
+			}
+		}
+
+		if count != stop {
+			t.Fatalf("All yielded %d times, expected a stop after %d", count, stop)
+		}
+	}
+}
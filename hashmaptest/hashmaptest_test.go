@@ -0,0 +1,107 @@
+package hashmaptest_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/EinfachAndy/hashmaps"
+	"github.com/EinfachAndy/hashmaps/hashmaptest"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+func intConfig(typ hashmaps.Type, opts ...hashmaps.Option) hashmaptest.Config[uint64, uint64] {
+	return hashmaptest.Config[uint64, uint64]{
+		New:   hashmaptest.Builtin[uint64, uint64](typ, opts...),
+		Copy:  hashmaps.Copy[uint64, uint64],
+		Key:   func(i int) uint64 { return uint64(i) + 1 },
+		Value: func(i int) uint64 { return uint64(i) },
+	}
+}
+
+func stringConfig(typ hashmaps.Type) hashmaptest.Config[string, string] {
+	return hashmaptest.Config[string, string]{
+		New:   hashmaptest.Builtin[string, string](typ),
+		Copy:  hashmaps.Copy[string, string],
+		Key:   func(i int) string { return "key" + strconv.Itoa(i) },
+		Value: strconv.Itoa,
+		Ops:   2000,
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	t.Parallel()
+
+	for _, typ := range []hashmaps.Type{
+		hashmaps.Hopscotch,
+		hashmaps.Robin,
+		hashmaps.Unordered,
+		hashmaps.Flat,
+		hashmaps.IndexMap,
+	} {
+		t.Run(typ.String(), func(t *testing.T) {
+			hashmaptest.Run(t, intConfig(typ))
+			hashmaptest.Run(t, stringConfig(typ))
+		})
+	}
+
+	t.Run("SoA", func(t *testing.T) {
+		hashmaptest.Run(t, intConfig(hashmaps.Robin, hashmaps.WithLayout(shared.SoA)))
+		hashmaptest.Run(t, intConfig(hashmaps.Hopscotch, hashmaps.WithLayout(shared.SoA)))
+	})
+}
+
+func TestRunHasher(t *testing.T) {
+	t.Parallel()
+
+	hashmaptest.RunHasher(t, shared.GetHasher[uint64](), func(i int) uint64 { return uint64(i) + 1 }, 1000)
+	hashmaptest.RunHasher(t, shared.GetHasher[string](), func(i int) string { return strconv.Itoa(i + 1) }, 1000)
+}
+
+func TestRunEqual(t *testing.T) {
+	t.Parallel()
+
+	var (
+		hasher = shared.GetHasher[string]()
+		keys   []string
+	)
+
+	for i := 0; i < 100; i++ {
+		key := "Key" + strconv.Itoa(i)
+		keys = append(keys, key, strings.ToUpper(key), strings.ToLower(key))
+	}
+
+	hashmaptest.RunEqual(t, func(key string) uintptr { return hasher(strings.ToLower(key)) }, strings.EqualFold, keys)
+
+	var slices [][]byte
+	for _, key := range keys {
+		slices = append(slices, []byte(key))
+	}
+
+	hashmaptest.RunEqual(t, func(key []byte) uintptr { return hasher(string(key)) }, bytes.Equal, slices)
+}
+
+func FuzzHopscotch(f *testing.F) {
+	hashmaptest.Fuzz(f, intConfig(hashmaps.Hopscotch))
+}
+
+func FuzzRobin(f *testing.F) {
+	hashmaptest.Fuzz(f, intConfig(hashmaps.Robin))
+}
+
+func FuzzUnordered(f *testing.F) {
+	hashmaptest.Fuzz(f, intConfig(hashmaps.Unordered))
+}
+
+func FuzzFlat(f *testing.F) {
+	hashmaptest.Fuzz(f, intConfig(hashmaps.Flat))
+}
+
+func FuzzIndexMap(f *testing.F) {
+	hashmaptest.Fuzz(f, intConfig(hashmaps.IndexMap))
+}
+
+func FuzzString(f *testing.F) {
+	hashmaptest.Fuzz(f, stringConfig(hashmaps.Robin))
+}
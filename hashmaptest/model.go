@@ -0,0 +1,111 @@
+package hashmaptest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EinfachAndy/hashmaps"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// model compares a hashmap under test with a golang map.
+type model[K comparable, V any] struct {
+	t   *testing.T
+	cfg Config[K, V]
+	m   hashmaps.Map[K, V]
+	std map[K]V
+}
+
+func newModel[K comparable, V any](t *testing.T, cfg Config[K, V], hasher shared.HashFn[K]) *model[K, V] {
+	return &model[K, V]{t: t, cfg: cfg, m: cfg.New(hasher), std: make(map[K]V)}
+}
+
+// step executes the operation 'op' with the key of index 'k'
+// and the value of index 'v'. Puts are executed twice as often.
+func (m *model[K, V]) step(op, k, v int) {
+	switch op {
+	case 0:
+		m.get(k)
+	case 1, 2:
+		m.put(k, v)
+	default:
+		m.remove(k)
+	}
+}
+
+func (m *model[K, V]) get(k int) {
+	m.t.Helper()
+
+	var (
+		key     = m.cfg.Key(k)
+		v1, ok1 = m.m.Get(key)
+		v2, ok2 = m.std[key]
+	)
+
+	if ok1 != ok2 {
+		m.t.Fatalf("Get(%v) returned %v, expected %v", key, ok1, ok2)
+	}
+
+	if !reflect.DeepEqual(v1, v2) {
+		m.t.Fatalf("Get(%v) returned value %v, expected %v", key, v1, v2)
+	}
+}
+
+func (m *model[K, V]) put(k, v int) {
+	m.t.Helper()
+
+	var (
+		key      = m.cfg.Key(k)
+		val      = m.cfg.Value(v)
+		_, wasIn = m.std[key]
+	)
+
+	m.std[key] = val
+
+	if isNew := m.m.Put(key, val); isNew == wasIn {
+		m.t.Fatalf("Put(%v) returned %v, expected %v", key, isNew, !wasIn)
+	}
+
+	m.get(k)
+}
+
+func (m *model[K, V]) remove(k int) {
+	m.t.Helper()
+
+	var (
+		key      = m.cfg.Key(k)
+		_, wasIn = m.std[key]
+	)
+
+	delete(m.std, key)
+
+	if found := m.m.Remove(key); found != wasIn {
+		m.t.Fatalf("Remove(%v) returned %v, expected %v", key, found, wasIn)
+	}
+
+	m.get(k)
+}
+
+func (m *model[K, V]) clear() {
+	m.t.Helper()
+
+	m.m.Clear()
+	clear(m.std)
+}
+
+func (m *model[K, V]) copy() *model[K, V] {
+	cpy := &model[K, V]{t: m.t, cfg: m.cfg, m: m.cfg.Copy(m.m), std: make(map[K]V, len(m.std))}
+
+	for key, val := range m.std {
+		cpy.std[key] = val
+	}
+
+	return cpy
+}
+
+// verify compares the whole content of the hashmaps.
+func (m *model[K, V]) verify() {
+	m.t.Helper()
+
+	Verify(m.t, m.m, m.std)
+}
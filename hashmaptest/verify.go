@@ -0,0 +1,51 @@
+package hashmaptest
+
+import (
+	"reflect"
+	"testing"
+)
+
+// View is the read only method set of the hashmaps and their snapshots.
+type View[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Each(fn func(key K, val V) bool)
+	Size() int
+}
+
+// Verify compares the whole content of the hashmap with the golang map 'want'.
+// The size, the key-value pairs visited by `Each` and the lookups by `Get` must match.
+func Verify[K comparable, V any](t testing.TB, m View[K, V], want map[K]V) {
+	t.Helper()
+
+	if m.Size() != len(want) {
+		t.Fatalf("Size() returned %d, expected %d", m.Size(), len(want))
+	}
+
+	seen := make(map[K]struct{}, len(want))
+
+	m.Each(func(key K, val V) bool {
+		if _, ok := seen[key]; ok {
+			t.Fatalf("Each visited key %v twice", key)
+		}
+
+		seen[key] = struct{}{}
+
+		if expected, ok := want[key]; !ok {
+			t.Fatalf("Each visited unknown key %v", key)
+		} else if !reflect.DeepEqual(val, expected) {
+			t.Fatalf("Each visited key %v with value %v, expected %v", key, val, expected)
+		}
+
+		return false
+	})
+
+	if len(seen) != len(want) {
+		t.Fatalf("Each visited %d keys, expected %d", len(seen), len(want))
+	}
+
+	for key, expected := range want {
+		if val, ok := m.Get(key); !ok || !reflect.DeepEqual(val, expected) {
+			t.Fatalf("Get(%v) returned (%v, %v), expected (%v, true)", key, val, ok, expected)
+		}
+	}
+}
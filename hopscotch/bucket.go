@@ -1,61 +1,93 @@
 package hopscotch
 
-type bucket[K comparable, V any] struct {
-	hopInfo uint64 // stores the neighborhood and the state of the reserved bits
+import "unsafe"
+
+// Width is the constraint for the integer type that stores the hop info
+// of a bucket. The number of bits limits the size of the neighborhood.
+type Width interface {
+	~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+type bucket[K comparable, V any, H Width] struct {
+	hopInfo hopInfo[H]
 	key     K
 	val     V
 }
 
+// hopInfo stores the neighborhood and the state of the reserved bits
+type hopInfo[H Width] struct {
+	bits H
+}
+
 const (
-	reservedBits        = uintptr(1)        // number of reserved bits within the hop info
-	occupyBit           = uint64(1)         // bit mask for the occupy bit
-	maxNeighborhoodSize = 64 - reservedBits // max size of H (neighborhood)
+	reservedBits = uintptr(2) // number of reserved bits within the hop info
+	occupyBit    = 1          // bit mask for the occupy bit
+	overflowBit  = 2          // bit mask for the overflow bit
 )
 
-// flip returns the opposite bit mask
+// maxNeighborhoodSize returns the max size of H (neighborhood)
 //
 //go:inline
-func flip(a uint64) uint64 {
-	a ^= 0xFFFFFFFFFFFFFFFF
-	return a
+func maxNeighborhoodSize[H Width]() uintptr {
+	var h H
+	return 8*unsafe.Sizeof(h) - reservedBits
 }
 
 // set the state of v at the i-th position within the neighborhood
 //
 //go:inline
-func (b *bucket[K, V]) set(i uintptr, v bool) {
-	mask := uint64(1) << (i + reservedBits)
+func (h *hopInfo[H]) set(i uintptr, v bool) {
+	mask := H(1) << (i + reservedBits)
 	if v {
-		b.hopInfo |= mask
+		h.bits |= mask
 	} else {
-		b.hopInfo &= flip(mask)
+		h.bits &^= mask
 	}
 }
 
 // getNeighborhood returns the neighborhood bit mask
 //
 //go:inline
-func (b *bucket[K, V]) getNeighborhood() uint64 {
-	return b.hopInfo >> uint64(reservedBits)
+func (h *hopInfo[H]) getNeighborhood() uint64 {
+	return uint64(h.bits >> reservedBits)
 }
 
 // returns true if the bucket is empty
 //
 //go:inline
-func (b *bucket[K, V]) isEmpty() bool {
-	return (b.hopInfo & occupyBit) == 0
+func (h *hopInfo[H]) isEmpty() bool {
+	return (h.bits & occupyBit) == 0
 }
 
 // release marks the bucket as empty
 //
 //go:inline
-func (b *bucket[K, V]) release() {
-	b.hopInfo &= flip(occupyBit)
+func (h *hopInfo[H]) release() {
+	h.bits &^= occupyBit
 }
 
 // occupy marks the bucket as not empty
 //
 //go:inline
-func (b *bucket[K, V]) occupy() {
-	b.hopInfo |= occupyBit
+func (h *hopInfo[H]) occupy() {
+	h.bits |= occupyBit
+}
+
+// hasOverflow returns true if keys of this home bucket are
+// stored in the overflow list
+//
+//go:inline
+func (h *hopInfo[H]) hasOverflow() bool {
+	return (h.bits & overflowBit) != 0
+}
+
+// setOverflow sets the state of the overflow bit
+//
+//go:inline
+func (h *hopInfo[H]) setOverflow(v bool) {
+	if v {
+		h.bits |= overflowBit
+	} else {
+		h.bits &^= overflowBit
+	}
 }
@@ -6,18 +6,48 @@ import (
 	"github.com/EinfachAndy/hashmaps/shared"
 )
 
+const (
+	// DefaultNeighborhoodSize is the initial size of the neighborhood.
+	DefaultNeighborhoodSize = 4
+	// DefaultOverflowSize is the default upper bound of the overflow list.
+	DefaultOverflowSize = 8
+)
+
 // Hopscotch is a hashmap implementation which uses open addressing,
 // where collisions are managed within a limited neighborhood. That is
 // implemented as a dynamically growing bitmap with a default
-// size of 4 and a upper bound of 63. From this it follows a constant
+// size of 4 and a upper bound of 62. From this it follows a constant
 // lookup time for the Get function. To achieve this invariant
 // linear probing is used for finding an empty slot in the hashmap,
 // if the next empty slot is not within the size of the neighborhood,
 // subsequent swap of closer buckets are done or the size of the
-// neighborhood is increased.
+// neighborhood is increased. Keys that still do not fit into their
+// neighborhood are stored in a small overflow list, until it is full
+// and the hashmap grows. Large values can be stored in a separate
+// array, see `Layout`.
 type Hopscotch[K comparable, V any] struct {
-	buckets []bucket[K, V]
-	hasher  shared.HashFn[K]
+	Compact[K, V, uint64]
+}
+
+// Compact is a `Hopscotch` hashmap with a configurable width of the
+// neighborhood bitmap. Two bits of H are reserved, so the neighborhood
+// is bounded by 6, 14, 30 or 62 buckets. A smaller width reduces the
+// memory consumption per bucket, but less keys with the same hash value
+// fit into the neighborhood. Growing does not separate keys with the same
+// hash value, so these keys are stored in the overflow list beyond its
+// upper bound and the lookup of them takes linear time, see `OverflowSize`.
+type Compact[K comparable, V any, H Width] struct {
+	core[K, V, H, shared.AoSStorage]
+}
+
+// core implements the `Compact` hashmap for the storage S of the buckets.
+type core[K comparable, V any, H Width, S shared.Storage] struct {
+	// soa is set, if the hashmap uses the `shared.SoA` layout.
+	// All operations are forwarded to it.
+	soa *core[K, V, H, shared.SoAStorage]
+
+	table  table[K, V, H, S]
+	hasher shared.HashFn[K]
 	// length stores the current inserted elements
 	length uintptr
 	// capMinus1 is used for a bitwise AND on the hash value,
@@ -26,6 +56,13 @@ type Hopscotch[K comparable, V any] struct {
 	neighborhoodSize uintptr
 	nextResize       uintptr
 	maxLoad          float32
+	// overflow stores keys that can not satisfy the neighborhood invariant
+	overflow    []overflowEntry[K, V]
+	maxOverflow uintptr
+	// sortKeys sorts the keys of `MarshalJSON`
+	sortKeys bool
+	// guard rejects structural changes during `EachMut`
+	guard shared.Guard
 }
 
 // New creates a ready to use `Hopscotch` hashmap with default settings.
@@ -35,62 +72,105 @@ func New[K comparable, V any]() *Hopscotch[K, V] {
 
 // NewWithHasher same as `NewHopscotch` but with a given hash function.
 func NewWithHasher[K comparable, V any](hasher shared.HashFn[K]) *Hopscotch[K, V] {
-	const (
-		DefaultNeighborhoodSize = 4 // must be pow of 2
-	)
+	m := &Hopscotch[K, V]{}
+	m.init(hasher)
 
-	m := &Hopscotch[K, V]{
-		hasher:           hasher,
-		neighborhoodSize: DefaultNeighborhoodSize,
-		maxLoad:          shared.DefaultMaxLoad,
-	}
+	return m
+}
 
-	m.Reserve(shared.DefaultSize)
+// NewCompact creates a ready to use `Compact` hashmap with default settings.
+func NewCompact[K comparable, V any, H Width]() *Compact[K, V, H] {
+	return NewCompactWithHasher[K, V, H](shared.GetHasher[K]())
+}
+
+// NewCompactWithHasher same as `NewCompact` but with a given hash function.
+func NewCompactWithHasher[K comparable, V any, H Width](hasher shared.HashFn[K]) *Compact[K, V, H] {
+	m := &Compact[K, V, H]{}
+	m.init(hasher)
 
 	return m
 }
 
+func (m *Compact[K, V, H]) init(hasher shared.HashFn[K]) {
+	if soa, _ := shared.UseSoA[V](shared.AutoLayout); soa {
+		m.soa = &core[K, V, H, shared.SoAStorage]{}
+		m.soa.init(hasher)
+	} else {
+		m.core.init(hasher)
+	}
+}
+
+func (m *core[K, V, H, S]) init(hasher shared.HashFn[K]) {
+	m.hasher = hasher
+	m.neighborhoodSize = DefaultNeighborhoodSize
+	m.maxOverflow = DefaultOverflowSize
+	m.maxLoad = shared.DefaultMaxLoad
+
+	m.Reserve(shared.DefaultSize)
+}
+
 // grow doubles the size size of the hashmap.
 //
 //go:inline
-func (m *Hopscotch[K, V]) grow() {
+func (m *core[K, V, H, S]) grow() {
 	m.resize(2 * (m.capMinus1 + 1))
 }
 
-func (m *Hopscotch[K, V]) resize(n uintptr) {
-	nmap := Hopscotch[K, V]{
-		buckets:          make([]bucket[K, V], n+m.neighborhoodSize),
+// resize rebuilds the hashmap with `n` buckets.
+func (m *core[K, V, H, S]) resize(n uintptr) {
+	nmap := rebuild[S](m, n)
+
+	// update current map
+	m.table = nmap.table
+	m.overflow = nmap.overflow
+	m.capMinus1 = nmap.capMinus1
+	m.nextResize = nmap.nextResize
+	m.neighborhoodSize = nmap.neighborhoodSize
+}
+
+// rebuild returns a copy of the hashmap `m` with `n` buckets in the storage T.
+func rebuild[T, S shared.Storage, K comparable, V any, H Width](m *core[K, V, H, S], n uintptr) core[K, V, H, T] {
+	nmap := core[K, V, H, T]{
+		table:            newTable[K, V, H, T](n+m.neighborhoodSize, m.table.gens.Enabled()),
 		hasher:           m.hasher,
 		length:           m.length,
 		capMinus1:        n - 1,
 		neighborhoodSize: m.neighborhoodSize,
 		maxLoad:          m.maxLoad,
-		nextResize:       uintptr(float32(n) * m.maxLoad),
+		nextResize:       shared.Threshold(n, m.maxLoad),
+		maxOverflow:      m.maxOverflow,
+		sortKeys:         m.sortKeys,
 	}
 
-	for i := range m.buckets {
-		if !m.buckets[i].isEmpty() {
-			homeIdx := nmap.hasher(m.buckets[i].key) & nmap.capMinus1
-			nmap.emplace(m.buckets[i].key, m.buckets[i].val, homeIdx)
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if !m.table.info(i).isEmpty() {
+			key := m.table.key(i)
+			homeIdx := nmap.hasher(key) & nmap.capMinus1
+			nmap.emplace(key, *m.table.value(i), homeIdx)
 		}
 	}
 
-	// update current map
-	m.buckets = nmap.buckets
-	m.capMinus1 = nmap.capMinus1
-	m.nextResize = nmap.nextResize
-	m.neighborhoodSize = nmap.neighborhoodSize
+	for i := range m.overflow {
+		homeIdx := nmap.hasher(m.overflow[i].key) & nmap.capMinus1
+		nmap.emplace(m.overflow[i].key, m.overflow[i].val, homeIdx)
+	}
+
+	return nmap
 }
 
 // Reserve sets the number of buckets to the most appropriate to contain at least n elements.
 // If n is lower than that, the function may have no effect.
-func (m *Hopscotch[K, V]) Reserve(n uintptr) {
-	var (
-		needed = uintptr(float32(n) / m.maxLoad)
-		newCap = uintptr(shared.NextPowerOf2(uint64(needed)))
-	)
+func (m *core[K, V, H, S]) Reserve(n uintptr) {
+	if m.soa != nil {
+		m.soa.Reserve(n)
+		return
+	}
 
-	if uintptr(cap(m.buckets)) < newCap {
+	m.guard.Check()
+
+	newCap := shared.Capacity(n, m.maxLoad)
+
+	if m.table.len() < newCap {
 		m.resize(newCap)
 	}
 }
@@ -99,11 +179,11 @@ func (m *Hopscotch[K, V]) Reserve(n uintptr) {
 // This function has a constant runtime.
 //
 //go:inline
-func (m *Hopscotch[K, V]) search(homeIdx uintptr, key K) (uintptr, bool) {
-	neighborhood := m.buckets[homeIdx].getNeighborhood()
+func (m *core[K, V, H, S]) search(homeIdx uintptr, key K) (uintptr, bool) {
+	neighborhood := m.table.info(homeIdx).getNeighborhood()
 	for neighborhood != 0 {
 		if (neighborhood & 1) == 1 {
-			if m.buckets[homeIdx].key == key {
+			if m.table.key(homeIdx) == key {
 				return homeIdx, true
 			}
 		}
@@ -117,7 +197,11 @@ func (m *Hopscotch[K, V]) search(homeIdx uintptr, key K) (uintptr, bool) {
 }
 
 // Get returns the value stored for this key, or false if there is no such value.
-func (m *Hopscotch[K, V]) Get(key K) (V, bool) {
+func (m *core[K, V, H, S]) Get(key K) (V, bool) {
+	if m.soa != nil {
+		return m.soa.Get(key)
+	}
+
 	var (
 		homeIdx    = m.hasher(key) & m.capMinus1
 		idx, found = m.search(homeIdx, key)
@@ -125,7 +209,13 @@ func (m *Hopscotch[K, V]) Get(key K) (V, bool) {
 	)
 
 	if found {
-		return m.buckets[idx].val, true
+		return *m.table.value(idx), true
+	}
+
+	if m.table.info(homeIdx).hasOverflow() {
+		if i := m.searchOverflow(key); i >= 0 {
+			return m.overflow[i].val, true
+		}
 	}
 
 	return v, false
@@ -137,26 +227,25 @@ func (m *Hopscotch[K, V]) Get(key K) (V, bool) {
 // is a in-out variable, that is updated, if the movement was successful.
 //
 //go:inline
-func (m *Hopscotch[K, V]) moveCloser(emptyIdx *uintptr) bool {
+func (m *core[K, V, H, S]) moveCloser(emptyIdx *uintptr) bool {
 	start := *emptyIdx - (m.neighborhoodSize - 1)
 
 	for homeIdx := start; homeIdx < *emptyIdx; homeIdx++ {
-		neighborhood := m.buckets[homeIdx].getNeighborhood()
+		neighborhood := m.table.info(homeIdx).getNeighborhood()
 		for cIdx := homeIdx; neighborhood != 0 && cIdx < *emptyIdx; cIdx++ {
 			if (neighborhood & 1) == 1 {
 				distance := cIdx - homeIdx
 				// found a candidate, mark it as empty
-				m.buckets[cIdx].release()
+				m.table.editInfo(cIdx).release()
 
 				// move the candidate to the empty bucket
-				m.buckets[*emptyIdx].occupy()
-				m.buckets[*emptyIdx].key = m.buckets[cIdx].key
-				m.buckets[*emptyIdx].val = m.buckets[cIdx].val
+				m.table.editInfo(*emptyIdx).occupy()
+				m.table.move(cIdx, *emptyIdx)
 
 				// update the neighborhood of the home bucket,
 				// because we moved the empty bucket closer
-				m.buckets[homeIdx].set(distance, false)
-				m.buckets[homeIdx].set(*emptyIdx-homeIdx, true)
+				m.table.editInfo(homeIdx).set(distance, false)
+				m.table.editInfo(homeIdx).set(*emptyIdx-homeIdx, true)
 
 				// announce the new empty index
 				*emptyIdx = cIdx
@@ -174,38 +263,37 @@ func (m *Hopscotch[K, V]) moveCloser(emptyIdx *uintptr) bool {
 // increaseNeighborhood returns true if the Neighborhood could be increased.
 //
 //go:inline
-func (m *Hopscotch[K, V]) increaseNeighborhood() bool {
+func (m *core[K, V, H, S]) increaseNeighborhood() bool {
 	// move closer does not work, we need to find another solution!
-	const lastPow2 = 32
-	if m.neighborhoodSize < lastPow2 {
-		m.neighborhoodSize = 2 * m.neighborhoodSize
-		return true
+	maxSize := maxNeighborhoodSize[H]()
+	if m.neighborhoodSize >= maxSize {
+		return false
 	}
-	if m.neighborhoodSize == lastPow2 {
-		m.neighborhoodSize = maxNeighborhoodSize
-		return true
+
+	m.neighborhoodSize *= 2
+	if m.neighborhoodSize > maxSize {
+		m.neighborhoodSize = maxSize
 	}
 
-	return false
+	return true
 }
 
 // emplace adds the key-value pair to the hashmap. It does not check
 // the occurrence, so it expects that the give key is not already
-// in. Furthermore a resize or rehash can happen to achieve
-// the neighborhood invariant.
-func (m *Hopscotch[K, V]) emplace(key K, val V, homeIdx uintptr) {
+// in. Furthermore the key is moved to the overflow list or a resize
+// or rehash can happen to achieve the neighborhood invariant.
+func (m *core[K, V, H, S]) emplace(key K, val V, homeIdx uintptr) {
 START:
 	emptyIdx := homeIdx
 
 	// linear probing for the next empty bucket
 	for ; ; emptyIdx++ {
-		if emptyIdx == uintptr(cap(m.buckets)) {
-			// we reached the end of the bucket array, so we need to resize it
-			m.grow()
-			goto EMPLACE_AFTER_REHASH
+		if emptyIdx == m.table.len() {
+			// we reached the end of the bucket array
+			goto OVERFLOW
 		}
 
-		if m.buckets[emptyIdx].isEmpty() {
+		if m.table.info(emptyIdx).isEmpty() {
 			// we found a empty bucket for the next insert, we are done
 			break
 		}
@@ -219,10 +307,9 @@ START:
 		if distance < m.neighborhoodSize {
 			// we found an empty bucket within the neighborhood.
 			// we are finished and can emplace the key-value pair.
-			m.buckets[emptyIdx].occupy()
-			m.buckets[emptyIdx].key = key
-			m.buckets[emptyIdx].val = val
-			m.buckets[homeIdx].set(distance, true)
+			m.table.editInfo(emptyIdx).occupy()
+			m.table.store(emptyIdx, key, val)
+			m.table.editInfo(homeIdx).set(distance, true)
 
 			return
 		}
@@ -235,22 +322,42 @@ START:
 	}
 
 	// move closer does not work, we need to find another solution!
-	if !m.increaseNeighborhood() {
-		// that is the last hope to achieve the neighborhood invariant,
-		// but this case should happen really rare.
-		// Note: it is also possible to change the hash function here!
-		m.grow()
+	if m.increaseNeighborhood() {
+		goto START
 	}
 
-EMPLACE_AFTER_REHASH:
-	homeIdx = m.hasher(key) & m.capMinus1
+OVERFLOW:
+	if m.pushOverflow(key, val, homeIdx) {
+		return
+	}
+
+	hash := m.hasher(key)
+	if !m.separable(hash, homeIdx) {
+		// all keys of the home bucket share the hash value,
+		// so they would collide after any resize
+		m.appendOverflow(key, val, homeIdx)
+		return
+	}
+
+	// that is the last hope to achieve the neighborhood invariant,
+	// but this case should happen really rare.
+	// Note: it is also possible to change the hash function here!
+	m.grow()
+
+	homeIdx = hash & m.capMinus1
 	goto START
 }
 
 // Put adds the given key-value pair to the hashmap. If the key already exists its
 // value will be overwritten with the new value.
 // Returns true, if the element is a new item in the hashmap.
-func (m *Hopscotch[K, V]) Put(key K, val V) bool {
+func (m *core[K, V, H, S]) Put(key K, val V) bool {
+	if m.soa != nil {
+		return m.soa.Put(key, val)
+	}
+
+	m.guard.Check()
+
 	// check for resize
 	if m.length >= m.nextResize {
 		m.grow()
@@ -263,10 +370,17 @@ func (m *Hopscotch[K, V]) Put(key K, val V) bool {
 
 	if found {
 		// already inserted, update
-		m.buckets[idx].val = val
+		m.table.setValue(idx, val)
 		return false
 	}
 
+	if m.table.info(homeIdx).hasOverflow() {
+		if i := m.searchOverflow(key); i >= 0 {
+			m.overflow[i].val = val
+			return false
+		}
+	}
+
 	// emplace new key-value pair
 	m.length++
 	m.emplace(key, val, homeIdx)
@@ -276,84 +390,244 @@ func (m *Hopscotch[K, V]) Put(key K, val V) bool {
 
 // Remove removes the specified key-value pair from the hashmap.
 // Returns true, if the element was in the hashmap.
-func (m *Hopscotch[K, V]) Remove(key K) bool {
+func (m *core[K, V, H, S]) Remove(key K) bool {
+	if m.soa != nil {
+		return m.soa.Remove(key)
+	}
+
+	m.guard.Check()
+
 	var (
 		homeIdx    = m.hasher(key) & m.capMinus1
 		idx, found = m.search(homeIdx, key)
 	)
 
 	if !found {
+		if m.table.info(homeIdx).hasOverflow() {
+			return m.removeOverflow(key, homeIdx)
+		}
+
 		return false
 	}
 
 	distance := idx - homeIdx
 
-	m.buckets[homeIdx].set(distance, false)
-	m.buckets[idx].release()
+	m.table.editInfo(homeIdx).set(distance, false)
+	m.table.editInfo(idx).release()
 	m.length--
 
 	return true
 }
 
 // Clear removes all key-value pairs from the hashmap.
-func (m *Hopscotch[K, V]) Clear() {
-	for i := range m.buckets {
-		m.buckets[i].hopInfo = 0
+func (m *core[K, V, H, S]) Clear() {
+	if m.soa != nil {
+		m.soa.Clear()
+		return
 	}
 
+	m.guard.Check()
+	m.table.clear()
+
+	m.overflow = m.overflow[:0]
 	m.length = 0
 }
 
 // MaxLoad forces resizing if the ratio is reached.
 // Useful values are in range [0.5-0.9].
 // Returns ErrOutOfRange if `lf` is not in the open range (0.0,1.0).
-func (m *Hopscotch[K, V]) MaxLoad(lf float32) error {
+func (m *core[K, V, H, S]) MaxLoad(lf float32) error {
+	if m.soa != nil {
+		return m.soa.MaxLoad(lf)
+	}
+
 	if lf <= 0.0 || lf >= 1.0 {
 		return fmt.Errorf("%f: %w", lf, shared.ErrOutOfRange)
 	}
 
 	m.maxLoad = lf
-	m.nextResize = uintptr(float32(cap(m.buckets)) * lf)
+	m.nextResize = shared.Threshold(m.table.len(), lf)
+
+	return nil
+}
+
+// Layout changes the memory layout of the buckets, see `shared.Layout`.
+// The hashmap is rebuilt, if the layout changes.
+// Returns ErrOutOfRange if `l` is unknown.
+func (m *Compact[K, V, H]) Layout(l shared.Layout) error {
+	soa, err := shared.UseSoA[V](l)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case soa && m.soa == nil:
+		m.guard.Check()
+
+		newm := rebuild[shared.SoAStorage](&m.core, m.capMinus1+1)
+		m.core = core[K, V, H, shared.AoSStorage]{soa: &newm}
+	case !soa && m.soa != nil:
+		m.soa.guard.Check()
+		m.core = rebuild[shared.AoSStorage](m.soa, m.soa.capMinus1+1)
+	}
+
+	return nil
+}
+
+// NeighborhoodSize changes the size of the neighborhood, which grows
+// dynamically if needed. The hashmap is rebuilt, if the size changes.
+// Returns ErrOutOfRange if `n` is zero or exceeds the width of H.
+func (m *core[K, V, H, S]) NeighborhoodSize(n uintptr) error {
+	if m.soa != nil {
+		return m.soa.NeighborhoodSize(n)
+	}
+
+	if n == 0 || n > maxNeighborhoodSize[H]() {
+		return fmt.Errorf("neighborhood size %d: %w", n, shared.ErrOutOfRange)
+	}
+
+	if n != m.neighborhoodSize {
+		m.guard.Check()
+		m.neighborhoodSize = n
+		m.resize(m.capMinus1 + 1)
+	}
 
 	return nil
 }
 
+// OverflowSize changes the upper bound of the overflow list, which stores
+// keys that can not satisfy the neighborhood invariant. If the list is
+// full, the hashmap grows instead. Zero disables the overflow list.
+// The upper bound is exceeded by keys, that share the hash value with all
+// keys of their home bucket, because growing can not separate them.
+func (m *core[K, V, H, S]) OverflowSize(n uintptr) {
+	if m.soa != nil {
+		m.soa.OverflowSize(n)
+		return
+	}
+
+	m.guard.Check()
+
+	m.maxOverflow = n
+
+	if uintptr(len(m.overflow)) > n {
+		m.resize(m.capMinus1 + 1)
+	}
+}
+
+// LazyClear enables generation stamps, so `Clear` starts a new generation
+// in O(1) instead of walking all buckets. Buckets of an older generation
+// are treated as empty and the stamps are wiped every 255 calls of `Clear`.
+// The stamps cost one byte per bucket and the keys and values of the
+// cleared buckets are released only when they are overwritten.
+func (m *core[K, V, H, S]) LazyClear(on bool) {
+	if m.soa != nil {
+		m.soa.LazyClear(on)
+		return
+	}
+
+	m.table.lazyClear(on)
+}
+
 // Load return the current load of the hashmap.
-func (m *Hopscotch[K, V]) Load() float32 {
-	return float32(m.length) / float32(cap(m.buckets))
+func (m *core[K, V, H, S]) Load() float32 {
+	if m.soa != nil {
+		return m.soa.Load()
+	}
+
+	return float32(m.length) / float32(m.table.len())
 }
 
 // Size returns the number of items in the hashmap.
-func (m *Hopscotch[K, V]) Size() int {
+func (m *core[K, V, H, S]) Size() int {
+	if m.soa != nil {
+		return m.soa.Size()
+	}
+
 	return int(m.length)
 }
 
 // Copy returns a copy of this hashmap.
 func (m *Hopscotch[K, V]) Copy() *Hopscotch[K, V] {
-	newM := &Hopscotch[K, V]{
-		buckets:          make([]bucket[K, V], cap(m.buckets)),
+	return &Hopscotch[K, V]{*m.Compact.Copy()}
+}
+
+// Copy returns a copy of this hashmap.
+func (m *Compact[K, V, H]) Copy() *Compact[K, V, H] {
+	if m.soa != nil {
+		newm := m.soa.copy()
+		return &Compact[K, V, H]{core[K, V, H, shared.AoSStorage]{soa: &newm}}
+	}
+
+	return &Compact[K, V, H]{m.copy()}
+}
+
+func (m *core[K, V, H, S]) copy() core[K, V, H, S] {
+	return core[K, V, H, S]{
+		table:            m.table.copy(),
 		capMinus1:        m.capMinus1,
 		length:           m.length,
 		hasher:           m.hasher,
 		neighborhoodSize: m.neighborhoodSize,
 		maxLoad:          m.maxLoad,
 		nextResize:       m.nextResize,
+		overflow:         append([]overflowEntry[K, V](nil), m.overflow...),
+		maxOverflow:      m.maxOverflow,
+		sortKeys:         m.sortKeys,
 	}
-
-	copy(newM.buckets, m.buckets)
-
-	return newM
 }
 
 // Each calls 'fn' on every key-value pair in the hash map in no particular order.
 // If 'fn' returns true, the iteration stops.
-func (m *Hopscotch[K, V]) Each(fn func(key K, val V) bool) {
-	for i := range m.buckets {
-		if !m.buckets[i].isEmpty() {
-			if stop := fn(m.buckets[i].key, m.buckets[i].val); stop {
+func (m *core[K, V, H, S]) Each(fn func(key K, val V) bool) {
+	if m.soa != nil {
+		m.soa.Each(fn)
+		return
+	}
+
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if !m.table.info(i).isEmpty() {
+			if stop := fn(m.table.key(i), *m.table.value(i)); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+
+	for i := range m.overflow {
+		if stop := fn(m.overflow[i].key, m.overflow[i].val); stop {
+			// stop iteration
+			return
+		}
+	}
+}
+
+// EachMut calls 'fn' on every key-value pair in the hashmap in no particular order.
+// The value can be modified through the pointer, which is valid until 'fn' returns.
+// Structural changes like `Put`, `Remove` or `Clear` within 'fn' panic with
+// `shared.ErrIterating`. If 'fn' returns true, the iteration stops.
+func (m *core[K, V, H, S]) EachMut(fn func(key K, val *V) bool) {
+	if m.soa != nil {
+		m.soa.EachMut(fn)
+		return
+	}
+
+	m.guard.Enter()
+	defer m.guard.Leave()
+
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if !m.table.info(i).isEmpty() {
+			if stop := fn(m.table.key(i), m.table.editValue(i)); stop {
 				// stop iteration
 				return
 			}
 		}
 	}
+
+	for i := range m.overflow {
+		if stop := fn(m.overflow[i].key, &m.overflow[i].val); stop {
+			// stop iteration
+			return
+		}
+	}
 }
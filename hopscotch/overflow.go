@@ -0,0 +1,96 @@
+package hopscotch
+
+// overflowEntry is a key-value pair that does not fit into
+// the neighborhood of its home bucket.
+type overflowEntry[K comparable, V any] struct {
+	home uintptr
+	key  K
+	val  V
+}
+
+// searchOverflow returns the index of the key within the overflow list or -1.
+//
+//go:inline
+func (m *core[K, V, H, S]) searchOverflow(key K) int {
+	for i := range m.overflow {
+		if m.overflow[i].key == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// pushOverflow appends the key-value pair to the overflow list and
+// marks the home bucket. Returns false, if the overflow list is full.
+func (m *core[K, V, H, S]) pushOverflow(key K, val V, homeIdx uintptr) bool {
+	if uintptr(len(m.overflow)) >= m.maxOverflow {
+		return false
+	}
+
+	m.appendOverflow(key, val, homeIdx)
+
+	return true
+}
+
+// appendOverflow appends the key-value pair to the overflow list
+// regardless of its upper bound and marks the home bucket.
+func (m *core[K, V, H, S]) appendOverflow(key K, val V, homeIdx uintptr) {
+	m.overflow = append(m.overflow, overflowEntry[K, V]{home: homeIdx, key: key, val: val})
+	m.table.editInfo(homeIdx).setOverflow(true)
+}
+
+// separable returns true, if growing the hashmap can separate the keys of the
+// home bucket, because one of them has another hash value than `hash`. Keys
+// with the same hash value collide at any size, so growing would never end.
+// Without any key of the home bucket, the neighborhood is crowded by keys of
+// other buckets and growing spreads them.
+func (m *core[K, V, H, S]) separable(hash, homeIdx uintptr) bool {
+	neighborhood := m.table.info(homeIdx).getNeighborhood()
+	colliding := neighborhood != 0
+
+	for idx := homeIdx; neighborhood != 0; idx++ {
+		if (neighborhood&1) == 1 && m.hasher(m.table.key(idx)) != hash {
+			return true
+		}
+
+		neighborhood >>= 1
+	}
+
+	for i := range m.overflow {
+		if m.overflow[i].home == homeIdx {
+			if m.hasher(m.overflow[i].key) != hash {
+				return true
+			}
+
+			colliding = true
+		}
+	}
+
+	return !colliding
+}
+
+// removeOverflow removes the key from the overflow list. The overflow bit of
+// the home bucket is cleared, if no other key of the home bucket is left.
+func (m *core[K, V, H, S]) removeOverflow(key K, homeIdx uintptr) bool {
+	i := m.searchOverflow(key)
+	if i < 0 {
+		return false
+	}
+
+	last := len(m.overflow) - 1
+	m.overflow[i] = m.overflow[last]
+	m.overflow[last] = overflowEntry[K, V]{}
+	m.overflow = m.overflow[:last]
+	m.length--
+
+	for i := range m.overflow {
+		if m.overflow[i].home == homeIdx {
+			return true
+		}
+	}
+
+	m.table.editInfo(homeIdx).setOverflow(false)
+
+	return true
+}
@@ -0,0 +1,166 @@
+package hopscotch
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// Snapshot is a read-only view of a `Compact` hashmap at the time it was taken.
+// It shares the buckets with the hashmap, which copies a page of buckets only
+// before it modifies the page for the first time (copy-on-write). A resize or
+// `Clear` of the hashmap allocates new buckets, so the snapshot keeps the old ones.
+// A snapshot can be read concurrently to the modifications of the hashmap.
+type Snapshot[K comparable, V any, H Width] struct {
+	snapshot[K, V, H, shared.AoSStorage]
+}
+
+// snapshot implements the `Snapshot` for the storage S of the buckets.
+type snapshot[K comparable, V any, H Width, S shared.Storage] struct {
+	// soa is set, if the hashmap uses the `shared.SoA` layout.
+	// All operations are forwarded to it.
+	soa *snapshot[K, V, H, shared.SoAStorage]
+
+	table     table[K, V, H, S]
+	pages     *shared.Pages[table[K, V, H, S]]
+	hasher    shared.HashFn[K]
+	capMinus1 uintptr
+	length    uintptr
+	// overflow is a copy, because the list is small
+	overflow []overflowEntry[K, V]
+}
+
+// Snapshot returns a read-only view of the hashmap in O(1). Call `Release`
+// if the snapshot is no longer needed, otherwise the hashmap keeps copying
+// the modified pages for it.
+func (m *Compact[K, V, H]) Snapshot() *Snapshot[K, V, H] {
+	if m.soa != nil {
+		s := m.soa.snapshot()
+		return &Snapshot[K, V, H]{snapshot[K, V, H, shared.AoSStorage]{soa: &s}}
+	}
+
+	return &Snapshot[K, V, H]{m.snapshot()}
+}
+
+func (m *core[K, V, H, S]) snapshot() snapshot[K, V, H, S] {
+	m.guard.Check()
+
+	if m.table.cow == nil {
+		m.table.cow = shared.NewCOW[table[K, V, H, S]](m.table.len())
+	}
+
+	s := snapshot[K, V, H, S]{
+		table:     m.table,
+		pages:     m.table.cow.Snapshot(),
+		hasher:    m.hasher,
+		capMinus1: m.capMinus1,
+		length:    m.length,
+		overflow:  append([]overflowEntry[K, V](nil), m.overflow...),
+	}
+	s.table.cow = nil
+
+	return s
+}
+
+// at returns the table, that holds the i-th bucket, and the index within this table.
+//
+//go:inline
+func (s *snapshot[K, V, H, S]) at(i uintptr) (*table[K, V, H, S], uintptr) {
+	if p := s.pages.Page(i); p != nil {
+		return p, i & shared.PageMask
+	}
+
+	return &s.table, i
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (s *snapshot[K, V, H, S]) Get(key K) (V, bool) {
+	if s.soa != nil {
+		return s.soa.Get(key)
+	}
+
+	var (
+		homeIdx = s.hasher(key) & s.capMinus1
+		v       V
+	)
+
+	s.pages.RLock()
+	defer s.pages.RUnlock()
+
+	t, i := s.at(homeIdx)
+	info := *t.info(i)
+
+	for idx, neighborhood := homeIdx, info.getNeighborhood(); neighborhood != 0; idx++ {
+		if (neighborhood & 1) == 1 {
+			if t, i := s.at(idx); t.key(i) == key {
+				return *t.value(i), true
+			}
+		}
+
+		neighborhood >>= 1
+	}
+
+	if info.hasOverflow() {
+		for i := range s.overflow {
+			if s.overflow[i].key == key {
+				return s.overflow[i].val, true
+			}
+		}
+	}
+
+	return v, false
+}
+
+// Size returns the number of items in the snapshot.
+func (s *snapshot[K, V, H, S]) Size() int {
+	if s.soa != nil {
+		return s.soa.Size()
+	}
+
+	return int(s.length)
+}
+
+// Each calls 'fn' on every key-value pair in the snapshot in no particular order.
+// If 'fn' returns true, the iteration stops.
+// The hashmap may be modified within 'fn'.
+func (s *snapshot[K, V, H, S]) Each(fn func(key K, val V) bool) {
+	if s.soa != nil {
+		s.soa.Each(fn)
+		return
+	}
+
+	for idx := uintptr(0); idx < s.table.len(); idx++ {
+		if k, v, ok := s.load(idx); ok {
+			if stop := fn(k, v); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+
+	for i := range s.overflow {
+		if stop := fn(s.overflow[i].key, s.overflow[i].val); stop {
+			// stop iteration
+			return
+		}
+	}
+}
+
+// load returns the key-value pair of the i-th bucket, or false if it is empty.
+func (s *snapshot[K, V, H, S]) load(idx uintptr) (K, V, bool) {
+	s.pages.RLock()
+	defer s.pages.RUnlock()
+
+	t, i := s.at(idx)
+
+	return t.key(i), *t.value(i), !t.info(i).isEmpty()
+}
+
+// Release unregisters the snapshot from the hashmap.
+// The snapshot must not be used afterwards.
+func (s *snapshot[K, V, H, S]) Release() {
+	if s.soa != nil {
+		s.soa.Release()
+		return
+	}
+
+	s.pages.Release()
+}
@@ -0,0 +1,236 @@
+package hopscotch
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// table stores the buckets of the hashmap. Depending on the storage S the
+// buckets are interleaved in one array (AoS) or the hop infos, keys and
+// values live in separate arrays (SoA). All accesses to the buckets are
+// done through the table, so the hashmap itself is layout agnostic.
+type table[K comparable, V any, H Width, S shared.Storage] struct {
+	// buckets is used by the AoS layout
+	buckets []bucket[K, V, H]
+	// infos, keys and values are used by the SoA layout
+	infos  []hopInfo[H]
+	keys   []K
+	values []V
+	// cow is set, if snapshots share the buckets, see `preserve`
+	cow *shared.COW[table[K, V, H, S]]
+	// gens is enabled by `LazyClear`, the hop info of a stale bucket is `stale`
+	gens  shared.Generations
+	stale hopInfo[H]
+}
+
+//go:inline
+func newTable[K comparable, V any, H Width, S shared.Storage](capacity uintptr, lazy bool) table[K, V, H, S] {
+	var t table[K, V, H, S]
+
+	if lazy {
+		t.gens = shared.NewGenerations(capacity)
+	}
+
+	if !shared.IsSoA[S]() {
+		t.buckets = make([]bucket[K, V, H], capacity)
+		return t
+	}
+
+	t.infos = make([]hopInfo[H], capacity)
+	t.keys = make([]K, capacity)
+	t.values = make([]V, capacity)
+
+	return t
+}
+
+// len returns the number of buckets.
+//
+//go:inline
+func (t *table[K, V, H, S]) len() uintptr {
+	if shared.IsSoA[S]() {
+		return uintptr(len(t.infos))
+	}
+
+	return uintptr(len(t.buckets))
+}
+
+// preserve must be called before the i-th bucket is modified.
+// It copies the page of the bucket for the snapshots, that share it.
+//
+//go:inline
+func (t *table[K, V, H, S]) preserve(i uintptr) {
+	if t.cow != nil && !t.cow.Preserved(i) {
+		t.cow.Preserve(i, t.len(), t.copyRange)
+	}
+}
+
+// renew must be called before the i-th bucket is modified. It resets
+// the hop info of a stale bucket and moves it into the current generation.
+//
+//go:inline
+func (t *table[K, V, H, S]) renew(i uintptr) {
+	if t.gens.Stale(i) {
+		*t.rawInfo(i) = hopInfo[H]{}
+		t.gens.Stamp(i)
+	}
+}
+
+// editInfo returns a pointer to the hop info of the i-th bucket for modifications.
+//
+//go:inline
+func (t *table[K, V, H, S]) editInfo(i uintptr) *hopInfo[H] {
+	t.preserve(i)
+	t.renew(i)
+
+	return t.rawInfo(i)
+}
+
+// info returns a read only pointer to the hop info of the i-th bucket.
+//
+//go:inline
+func (t *table[K, V, H, S]) info(i uintptr) *hopInfo[H] {
+	if t.gens.Stale(i) {
+		return &t.stale
+	}
+
+	return t.rawInfo(i)
+}
+
+//go:inline
+func (t *table[K, V, H, S]) rawInfo(i uintptr) *hopInfo[H] {
+	if shared.IsSoA[S]() {
+		return &t.infos[i]
+	}
+
+	return &t.buckets[i].hopInfo
+}
+
+//go:inline
+func (t *table[K, V, H, S]) key(i uintptr) K {
+	if shared.IsSoA[S]() {
+		return t.keys[i]
+	}
+
+	return t.buckets[i].key
+}
+
+// value returns a pointer to the value of the i-th bucket.
+//
+//go:inline
+func (t *table[K, V, H, S]) value(i uintptr) *V {
+	if shared.IsSoA[S]() {
+		return &t.values[i]
+	}
+
+	return &t.buckets[i].val
+}
+
+//go:inline
+func (t *table[K, V, H, S]) setValue(i uintptr, val V) {
+	t.preserve(i)
+	*t.value(i) = val
+}
+
+// editValue returns a pointer to the value of the i-th bucket,
+// which can be modified without affecting a snapshot.
+//
+//go:inline
+func (t *table[K, V, H, S]) editValue(i uintptr) *V {
+	t.preserve(i)
+	return t.value(i)
+}
+
+// store overwrites the key-value pair of the i-th bucket.
+// The hop info is untouched.
+//
+//go:inline
+func (t *table[K, V, H, S]) store(i uintptr, key K, val V) {
+	t.preserve(i)
+	t.renew(i)
+
+	if shared.IsSoA[S]() {
+		t.keys[i] = key
+		t.values[i] = val
+	} else {
+		t.buckets[i].key = key
+		t.buckets[i].val = val
+	}
+}
+
+// move copies the key-value pair of the bucket `from` to the bucket `to`.
+//
+//go:inline
+func (t *table[K, V, H, S]) move(from, to uintptr) {
+	t.preserve(to)
+	t.renew(to)
+
+	if shared.IsSoA[S]() {
+		t.keys[to] = t.keys[from]
+		t.values[to] = t.values[from]
+	} else {
+		t.buckets[to].key = t.buckets[from].key
+		t.buckets[to].val = t.buckets[from].val
+	}
+}
+
+// clear marks all buckets as empty. If snapshots share
+// the buckets, a new table is allocated instead.
+func (t *table[K, V, H, S]) clear() {
+	if t.cow != nil {
+		*t = newTable[K, V, H, S](t.len(), t.gens.Enabled())
+		return
+	}
+
+	if t.gens.Enabled() {
+		t.gens.Next()
+		return
+	}
+
+	if shared.IsSoA[S]() {
+		for i := range t.infos {
+			t.infos[i] = hopInfo[H]{}
+		}
+	} else {
+		for i := range t.buckets {
+			t.buckets[i].hopInfo = hopInfo[H]{}
+		}
+	}
+}
+
+// lazyClear enables or disables the generation stamps. The hop
+// infos of the stale buckets are reset, before the stamps are dropped.
+func (t *table[K, V, H, S]) lazyClear(on bool) {
+	switch {
+	case on && !t.gens.Enabled():
+		t.gens = shared.NewGenerations(t.len())
+	case !on && t.gens.Enabled():
+		for i := uintptr(0); i < t.len(); i++ {
+			if t.gens.Stale(i) {
+				t.editInfo(i)
+			}
+		}
+
+		t.gens = shared.Generations{}
+	}
+}
+
+// copy returns a deep copy of the table.
+func (t *table[K, V, H, S]) copy() table[K, V, H, S] {
+	return t.copyRange(0, t.len())
+}
+
+// copyRange returns a deep copy of the buckets in range [lo,hi).
+func (t *table[K, V, H, S]) copyRange(lo, hi uintptr) table[K, V, H, S] {
+	if shared.IsSoA[S]() {
+		return table[K, V, H, S]{
+			infos:  append([]hopInfo[H](nil), t.infos[lo:hi]...),
+			keys:   append([]K(nil), t.keys[lo:hi]...),
+			values: append([]V(nil), t.values[lo:hi]...),
+			gens:   t.gens.Copy(lo, hi),
+		}
+	}
+
+	return table[K, V, H, S]{
+		buckets: append([]bucket[K, V, H](nil), t.buckets[lo:hi]...),
+		gens:    t.gens.Copy(lo, hi),
+	}
+}
@@ -0,0 +1,23 @@
+package indexmap
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// SortKeys enables sorted keys for `MarshalJSON`, which makes the output
+// reproducible. Otherwise the keys are written in the order of `Each`.
+func (m *IndexMap[K, V]) SortKeys(on bool) {
+	m.sortKeys = on
+}
+
+// MarshalJSON implements the `json.Marshaler` interface. The keys must be
+// strings, integers or implement `encoding.TextMarshaler`, see `encoding/json`.
+func (m *IndexMap[K, V]) MarshalJSON() ([]byte, error) {
+	return shared.MarshalJSON(m.Each, m.sortKeys)
+}
+
+// UnmarshalJSON implements the `json.Unmarshaler` interface. The members
+// are added by `Put` and the hashmap is sized by `Reserve` before.
+func (m *IndexMap[K, V]) UnmarshalJSON(data []byte) error {
+	return shared.UnmarshalJSON(data, m.Reserve, m.Put)
+}
@@ -0,0 +1,338 @@
+package indexmap
+
+import (
+	"fmt"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+const (
+	emptyBucket = -1
+)
+
+type entry[K comparable, V any] struct {
+	hash  uintptr
+	key   K
+	value V
+}
+
+// bucket is a slot of the hash index, it refers to an entry by its position.
+type bucket struct {
+	hash uintptr
+	pos  uintptr
+	// psl is the probe sequence length (PSL), see `robin.RobinHood`.
+	// -1 or `emptyBucket` signals a free slot.
+	psl int8
+}
+
+// IndexMap is a hashmap, that stores its key-value pairs densely in a slice in
+// insertion order. The hash index is a robin hood hashmap, that stores only the
+// positions of the entries. This results in a cache friendly iteration and
+// positional access, see `GetIndex` and `IndexOf`. The entries are never moved
+// during a resize, which makes the hashmap a good choice for large values.
+// Removing an entry with `Remove` moves the last entry to the free position,
+// use `ShiftRemove` to keep the insertion order.
+type IndexMap[K comparable, V any] struct {
+	entries []entry[K, V]
+	buckets []bucket
+	hasher  shared.HashFn[K]
+	// capMinus1 is used for a bitwise AND on the hash value,
+	// because the size of the underlying array is a power of two value
+	capMinus1  uintptr
+	nextResize uintptr
+
+	maxLoad float32
+	// sortKeys sorts the keys of `MarshalJSON`
+	sortKeys bool
+}
+
+//go:inline
+func newBucketArray(capacity uintptr) []bucket {
+	buckets := make([]bucket, capacity)
+
+	for i := range buckets {
+		buckets[i].psl = emptyBucket
+	}
+
+	return buckets
+}
+
+// New creates a ready to use `IndexMap` hashmap with default settings.
+func New[K comparable, V any]() *IndexMap[K, V] {
+	return NewWithHasher[K, V](shared.GetHasher[K]())
+}
+
+// NewWithHasher same as `New` but with a given hash function.
+func NewWithHasher[K comparable, V any](hasher shared.HashFn[K]) *IndexMap[K, V] {
+	m := &IndexMap[K, V]{
+		hasher:  hasher,
+		maxLoad: shared.DefaultMaxLoad,
+	}
+	m.Reserve(shared.DefaultSize)
+
+	return m
+}
+
+// search returns the bucket index of the key.
+//
+//go:inline
+func (m *IndexMap[K, V]) search(key K, hash uintptr) (uintptr, bool) {
+	idx := hash & m.capMinus1
+
+	for psl := int8(0); psl <= m.buckets[idx].psl; psl++ {
+		if m.buckets[idx].hash == hash && m.entries[m.buckets[idx].pos].key == key {
+			return idx, true
+		}
+		// next index
+		idx = (idx + 1) & m.capMinus1
+	}
+
+	return 0, false
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (m *IndexMap[K, V]) Get(key K) (V, bool) {
+	if idx, found := m.search(key, m.hasher(key)); found {
+		return m.entries[m.buckets[idx].pos].value, true
+	}
+
+	var v V
+
+	return v, false
+}
+
+// IndexOf returns the position of the key within the insertion order or -1.
+func (m *IndexMap[K, V]) IndexOf(key K) int {
+	if idx, found := m.search(key, m.hasher(key)); found {
+		return int(m.buckets[idx].pos)
+	}
+
+	return -1
+}
+
+// GetIndex returns the key-value pair at the position `i`,
+// or false if `i` is out of range.
+func (m *IndexMap[K, V]) GetIndex(i int) (K, V, bool) {
+	if i < 0 || i >= len(m.entries) {
+		var (
+			k K
+			v V
+		)
+
+		return k, v, false
+	}
+
+	return m.entries[i].key, m.entries[i].value, true
+}
+
+// Reserve sets the number of buckets to the most appropriate to contain at least n elements.
+// If n is lower than that, the function may have no effect.
+func (m *IndexMap[K, V]) Reserve(n uintptr) {
+	newCap := shared.Capacity(n, m.maxLoad)
+
+	if uintptr(cap(m.buckets)) < newCap {
+		m.resize(newCap)
+	}
+
+	if uintptr(cap(m.entries)) < n {
+		entries := make([]entry[K, V], len(m.entries), n)
+		copy(entries, m.entries)
+		m.entries = entries
+	}
+}
+
+// resize rebuilds the hash index, the entries are untouched.
+func (m *IndexMap[K, V]) resize(n uintptr) {
+	oldBuckets := m.buckets
+
+	m.buckets = newBucketArray(n)
+	m.capMinus1 = n - 1
+	m.nextResize = shared.Threshold(n, m.maxLoad)
+
+	for i := range oldBuckets {
+		if oldBuckets[i].psl != emptyBucket {
+			current := oldBuckets[i]
+			current.psl = 0
+			m.emplace(current)
+		}
+	}
+}
+
+// emplace applies the Robin Hood creed to all following buckets until a empty is found.
+//
+//go:inline
+func (m *IndexMap[K, V]) emplace(current bucket) {
+	idx := current.hash & m.capMinus1
+
+	for ; ; current.psl++ {
+		if m.buckets[idx].psl == emptyBucket {
+			m.buckets[idx] = current
+			return
+		}
+
+		if current.psl > m.buckets[idx].psl {
+			// swap values, apply the Robin Hood creed
+			current, m.buckets[idx] = m.buckets[idx], current
+		}
+
+		// next index
+		idx = (idx + 1) & m.capMinus1
+	}
+}
+
+// Put adds the given key-value pair to the end of the hashmap. If the key already
+// exists its value will be overwritten with the new value and the position is kept.
+// Returns true, if the element is a new item in the hashmap.
+func (m *IndexMap[K, V]) Put(key K, val V) bool {
+	if uintptr(len(m.entries)) >= m.nextResize {
+		m.resize(uintptr(cap(m.buckets)) * 2)
+	}
+
+	hash := m.hasher(key)
+	if idx, found := m.search(key, hash); found {
+		m.entries[m.buckets[idx].pos].value = val
+		return false // update already existing value
+	}
+
+	m.emplace(bucket{hash: hash, pos: uintptr(len(m.entries))})
+	m.entries = append(m.entries, entry[K, V]{hash: hash, key: key, value: val})
+
+	return true
+}
+
+// removeBucket removes the bucket and back shifts all following buckets
+// until a optimum or empty one is found.
+//
+//go:inline
+func (m *IndexMap[K, V]) removeBucket(idx uintptr) {
+	m.buckets[idx].psl = emptyBucket
+
+	next := (idx + 1) & m.capMinus1
+	for m.buckets[next].psl > 0 {
+		m.buckets[idx] = m.buckets[next]
+		m.buckets[idx].psl--
+		m.buckets[next].psl = emptyBucket
+		idx = next
+		next = (next + 1) & m.capMinus1
+	}
+}
+
+// Remove removes the specified key-value pair from the hashmap. The last entry
+// is moved to the position of the removed one, which is a O(1) operation but
+// changes the order. Returns true, if the element was in the hashmap.
+func (m *IndexMap[K, V]) Remove(key K) bool {
+	idx, found := m.search(key, m.hasher(key))
+	if !found {
+		return false
+	}
+
+	var (
+		pos  = m.buckets[idx].pos
+		last = uintptr(len(m.entries) - 1)
+	)
+
+	m.removeBucket(idx)
+
+	if pos != last {
+		// update the bucket of the last entry
+		lastIdx := m.entries[last].hash & m.capMinus1
+		for m.buckets[lastIdx].pos != last || m.buckets[lastIdx].psl == emptyBucket {
+			lastIdx = (lastIdx + 1) & m.capMinus1
+		}
+
+		m.buckets[lastIdx].pos = pos
+		m.entries[pos] = m.entries[last]
+	}
+
+	m.entries[last] = entry[K, V]{}
+	m.entries = m.entries[:last]
+
+	return true
+}
+
+// ShiftRemove removes the specified key-value pair from the hashmap. All following
+// entries are shifted, so the order is kept. This is a O(n) operation.
+// Returns true, if the element was in the hashmap.
+func (m *IndexMap[K, V]) ShiftRemove(key K) bool {
+	idx, found := m.search(key, m.hasher(key))
+	if !found {
+		return false
+	}
+
+	pos := m.buckets[idx].pos
+	m.removeBucket(idx)
+
+	for i := range m.buckets {
+		if m.buckets[i].psl != emptyBucket && m.buckets[i].pos > pos {
+			m.buckets[i].pos--
+		}
+	}
+
+	last := len(m.entries) - 1
+	copy(m.entries[pos:], m.entries[pos+1:])
+	m.entries[last] = entry[K, V]{}
+	m.entries = m.entries[:last]
+
+	return true
+}
+
+// Clear removes all key-value pairs from the hashmap.
+func (m *IndexMap[K, V]) Clear() {
+	for i := range m.buckets {
+		m.buckets[i].psl = emptyBucket
+	}
+
+	for i := range m.entries {
+		m.entries[i] = entry[K, V]{}
+	}
+
+	m.entries = m.entries[:0]
+}
+
+// Load return the current load of the hashmap.
+func (m *IndexMap[K, V]) Load() float32 {
+	return float32(len(m.entries)) / float32(cap(m.buckets))
+}
+
+// MaxLoad forces resizing if the ratio is reached.
+// Useful values are in range [0.5-0.9].
+// Returns ErrOutOfRange if `lf` is not in the open range (0.0,1.0).
+func (m *IndexMap[K, V]) MaxLoad(lf float32) error {
+	if lf <= 0.0 || lf >= 1.0 {
+		return fmt.Errorf("%f: %w", lf, shared.ErrOutOfRange)
+	}
+
+	m.maxLoad = lf
+	m.nextResize = shared.Threshold(uintptr(cap(m.buckets)), lf)
+
+	return nil
+}
+
+// Size returns the number of items in the hashmap.
+func (m *IndexMap[K, V]) Size() int {
+	return len(m.entries)
+}
+
+// Copy returns a copy of this hashmap.
+func (m *IndexMap[K, V]) Copy() *IndexMap[K, V] {
+	return &IndexMap[K, V]{
+		entries:    append([]entry[K, V](nil), m.entries...),
+		buckets:    append([]bucket(nil), m.buckets...),
+		hasher:     m.hasher,
+		capMinus1:  m.capMinus1,
+		nextResize: m.nextResize,
+		maxLoad:    m.maxLoad,
+		sortKeys:   m.sortKeys,
+	}
+}
+
+// Each calls 'fn' on every key-value pair in the hash map in insertion order.
+// If 'fn' returns true, the iteration stops.
+func (m *IndexMap[K, V]) Each(fn func(key K, val V) bool) {
+	for i := range m.entries {
+		if stop := fn(m.entries[i].key, m.entries[i].value); stop {
+			// stop iteration
+			return
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package hashmaps
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// MarshalJSON implements the `json.Marshaler` interface. The keys must be
+// strings, integers or implement `encoding.TextMarshaler`, see `encoding/json`.
+// The keys are written in the order of `Each`.
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return shared.MarshalJSON(m.Each, false)
+}
+
+// UnmarshalJSON implements the `json.Unmarshaler` interface. The hashmap must
+// be created by `NewAdapter` before, the members are added by `Put`.
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	return shared.UnmarshalJSON(data, m.Reserve, m.Put)
+}
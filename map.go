@@ -2,14 +2,56 @@
 package hashmaps
 
 import (
+	"fmt"
+
 	"github.com/EinfachAndy/hashmaps/flat"
 	"github.com/EinfachAndy/hashmaps/hopscotch"
+	"github.com/EinfachAndy/hashmaps/indexmap"
 	"github.com/EinfachAndy/hashmaps/robin"
 	"github.com/EinfachAndy/hashmaps/shared"
 	"github.com/EinfachAndy/hashmaps/unordered"
 )
 
+// Map is the common interface of all hashmaps of this module. The concrete
+// types provide further functions, e.g.: `Copy` or `Layout`.
+type Map[K comparable, V any] interface {
+	// Get returns the value stored for this key, or false if not found.
+	Get(key K) (V, bool)
+	// Put adds the given key-value pair to the hashmap.
+	// Returns true, if the element is a new item in the hashmap.
+	Put(key K, val V) bool
+	// Remove removes the specified key-value pair from the hashmap.
+	// Returns true, if the element was in the hashmap.
+	Remove(key K) bool
+	// Reserve sets the number of buckets to the most appropriate to contain at least n elements.
+	Reserve(n uintptr)
+	// Load return the current load of the hashmap.
+	Load() float32
+	// Clear removes all key-value pairs from the hashmap.
+	Clear()
+	// Size returns the number of items in the hashmap.
+	Size() int
+	// Each calls 'fn' on every key-value pair in the hashmap.
+	// If 'fn' returns true, the iteration stops.
+	Each(fn func(key K, val V) bool)
+	// MaxLoad forces resizing if the ratio is reached.
+	MaxLoad(lf float32) error
+}
+
+// compile time checks, that all hashmaps implement the interface
+var (
+	_ Map[int, int] = (*hopscotch.Hopscotch[int, int])(nil)
+	_ Map[int, int] = (*hopscotch.Compact[int, int, uint8])(nil)
+	_ Map[int, int] = (*robin.RobinHood[int, int])(nil)
+	_ Map[int, int] = (*unordered.Unordered[int, int])(nil)
+	_ Map[int, int] = (*unordered.Inline[int, int])(nil)
+	_ Map[int, int] = (*flat.Flat[int, int])(nil)
+	_ Map[int, int] = (*indexmap.IndexMap[int, int])(nil)
+)
+
 // HashMap is the basic hashmap interface as a set of function points.
+//
+// Deprecated: Use the `Map` interface, see `NewAdapter` for existing code.
 type HashMap[K comparable, V any] struct {
 	Get     func(key K) (V, bool)
 	Put     func(key K, val V) bool
@@ -22,6 +64,23 @@ type HashMap[K comparable, V any] struct {
 	MaxLoad func(lf float32) error
 }
 
+// NewAdapter returns the function pointers of the hashmap as `HashMap`.
+//
+// Deprecated: Use the `Map` interface.
+func NewAdapter[K comparable, V any](m Map[K, V]) *HashMap[K, V] {
+	return &HashMap[K, V]{
+		Get:     m.Get,
+		Put:     m.Put,
+		Remove:  m.Remove,
+		Reserve: m.Reserve,
+		Load:    m.Load,
+		Clear:   m.Clear,
+		Size:    m.Size,
+		Each:    m.Each,
+		MaxLoad: m.MaxLoad,
+	}
+}
+
 // Type specified the type of the hashmap.
 type Type int
 
@@ -30,9 +89,29 @@ const (
 	Robin     Type = 1
 	Unordered Type = 2
 	Flat      Type = 3
+	IndexMap  Type = 4
 )
 
+// String returns the name of the type.
+func (t Type) String() string {
+	switch t {
+	case Hopscotch:
+		return "Hopscotch"
+	case Robin:
+		return "Robin"
+	case Unordered:
+		return "Unordered"
+	case Flat:
+		return "Flat"
+	case IndexMap:
+		return "IndexMap"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
 // Config is used by the factory to create and configure a hashmap instance.
+// The fields are shortcuts for the common options, see `Option`.
 type Config[K comparable, V any] struct {
 	Type Type
 	// Size grows the hashmap to the desired size.
@@ -40,19 +119,32 @@ type Config[K comparable, V any] struct {
 	Size uintptr
 	// MaxLoad changes the load factor of the hashmap.
 	// This value is a trade-off between performance and memory consumption.
-	// If unset `DefaultMaxLoad` is used.
+	// If unset `DefaultMaxLoad` is used, see `WithMaxLoad`.
 	MaxLoad float32
 	// Hasher that is used. Must be configured for complex data types or slices.
 	// If unset a default hasher is used for golang basic types.
 	Hasher shared.HashFn[K]
+	// Algorithm selects the hash functions for golang basic types and Seed
+	// is their seed, see `WithAlgorithm`. The Hasher takes precedence.
+	Algorithm shared.Algorithm
+	Seed      uint64
 	// Empty is used by some hash hashmap implementations e.g.: flat hashmap
-	// to track empty buckets.
+	// to track empty buckets. It is ignored by the other types, see `WithEmptyKey`.
 	Empty K
+	// Layout selects the memory layout of the open addressing hashmaps.
+	// If unset the layout is chosen by the size of the values.
+	// It is ignored by the other types, see `WithLayout`.
+	Layout shared.Layout
+	// LazyClear makes `Clear` of the open addressing hashmaps O(1) by
+	// generation stamps. It is ignored by the other types, see `WithLazyClear`.
+	LazyClear bool
+	// SortKeys sorts the keys of `MarshalJSON` for a reproducible output.
+	SortKeys bool
 }
 
 // MustNewHashMap same as 'NewHashMap' but panics if and only if an error occurs.
-func MustNewHashMap[K comparable, V any](cfg Config[K, V]) *HashMap[K, V] {
-	m, err := NewHashMap(cfg)
+func MustNewHashMap[K comparable, V any](cfg Config[K, V], opts ...Option) Map[K, V] {
+	m, err := NewHashMap(cfg, opts...)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -60,71 +152,165 @@ func MustNewHashMap[K comparable, V any](cfg Config[K, V]) *HashMap[K, V] {
 }
 
 // NewHashMap is a factory function to instantiate different kind of generic
-// hashmap implementations. A struct with function pointers is used as
-// interface. In most cases the usage of the dedicate hashmap type is recommended.
-func NewHashMap[K comparable, V any](cfg Config[K, V]) (*HashMap[K, V], error) {
-	if cfg.Hasher == nil {
-		cfg.Hasher = shared.GetHasher[K]()
+// hashmap implementations, which are returned by the `Map` interface.
+// The options are applied after the fields of the config.
+// In most cases the usage of the dedicate hashmap type is recommended.
+// Returns ErrOutOfRange if the `Type` or the value of an option is invalid,
+// ErrUnsupported if an option is not supported by the `Type` and
+// ErrTypeMismatch if the key type of an option does not match K.
+func NewHashMap[K comparable, V any](cfg Config[K, V], opts ...Option) (Map[K, V], error) {
+	if cfg.Type < Hopscotch || cfg.Type > IndexMap {
+		return nil, fmt.Errorf("type %d: %w", cfg.Type, shared.ErrOutOfRange)
+	}
+
+	var o options
+
+	for _, opt := range append(configOptions(cfg), opts...) {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := o.check(cfg.Type); err != nil {
+		return nil, err
+	}
+
+	var (
+		hasher shared.HashFn[K]
+		empty  K
+		ok     bool
+		err    error
+	)
+
+	if o.hasher == nil {
+		if o.algorithm == shared.Default {
+			hasher = shared.GetHasher[K]()
+		} else if hasher, err = shared.GetSeededHasher[K](o.algorithm, o.seed); err != nil {
+			return nil, err
+		}
+	} else if hasher, ok = o.hasher.(shared.HashFn[K]); !ok {
+		return nil, fmt.Errorf("hasher %T: %w", o.hasher, shared.ErrTypeMismatch)
+	}
+
+	if o.empty != nil {
+		if empty, ok = o.empty.(K); !ok {
+			return nil, fmt.Errorf("empty key %T: %w", o.empty, shared.ErrTypeMismatch)
+		}
 	}
 
-	res := &HashMap[K, V]{}
+	var (
+		res    Map[K, V]
+		layout func(l shared.Layout) error
+		lazy   func(on bool)
+		sort   func(on bool)
+	)
 
 	switch cfg.Type {
 	case Hopscotch:
-		m := hopscotch.NewWithHasher[K, V](cfg.Hasher)
-		res.Clear = m.Clear
-		res.Each = m.Each
-		res.Get = m.Get
-		res.Load = m.Load
-		res.MaxLoad = m.MaxLoad
-		res.Put = m.Put
-		res.Remove = m.Remove
-		res.Reserve = m.Reserve
-		res.Size = m.Size
+		m := hopscotch.NewWithHasher[K, V](hasher)
+		res, layout, lazy, sort = m, m.Layout, m.LazyClear, m.SortKeys
+
+		if o.neighborhoodSize > 0 {
+			if err := m.NeighborhoodSize(o.neighborhoodSize); err != nil {
+				return nil, err
+			}
+		}
+
+		if o.overflowSize != nil {
+			m.OverflowSize(*o.overflowSize)
+		}
 	case Robin:
-		m := robin.NewWithHasher[K, V](cfg.Hasher)
-		res.Clear = m.Clear
-		res.Each = m.Each
-		res.Get = m.Get
-		res.Load = m.Load
-		res.MaxLoad = m.MaxLoad
-		res.Put = m.Put
-		res.Remove = m.Remove
-		res.Reserve = m.Reserve
-		res.Size = m.Size
+		m := robin.NewWithHasher[K, V](hasher)
+		res, layout, lazy, sort = m, m.Layout, m.LazyClear, m.SortKeys
+
+		if o.strategy != nil {
+			if err := m.SearchStrategy(*o.strategy); err != nil {
+				return nil, err
+			}
+		}
 	case Unordered:
-		m := unordered.NewWithHasher[K, V](cfg.Hasher)
-		res.Clear = m.Clear
-		res.Each = m.Each
-		res.Get = m.Get
-		res.Load = m.Load
-		res.MaxLoad = m.MaxLoad
-		res.Put = m.Put
-		res.Remove = m.Remove
-		res.Reserve = m.Reserve
-		res.Size = m.Size
+		m := unordered.NewWithHasher[K, V](hasher)
+		res, sort = m, m.SortKeys
 	case Flat:
-		m := flat.NewWithHasher[K, V](cfg.Empty, cfg.Hasher)
-		res.Clear = m.Clear
-		res.Each = m.Each
-		res.Get = m.Get
-		res.Load = m.Load
-		res.MaxLoad = m.MaxLoad
-		res.Put = m.Put
-		res.Remove = m.Remove
-		res.Reserve = m.Reserve
-		res.Size = m.Size
+		m := flat.NewWithHasher[K, V](empty, hasher)
+		res, layout, lazy, sort = m, m.Layout, m.LazyClear, m.SortKeys
+	case IndexMap:
+		m := indexmap.NewWithHasher[K, V](hasher)
+		res, sort = m, m.SortKeys
 	}
 
-	if cfg.MaxLoad > 0 {
-		if err := res.MaxLoad(cfg.MaxLoad); err != nil {
+	if layout != nil {
+		if err := layout(o.layout); err != nil {
+			return nil, err
+		}
+
+		lazy(o.lazyClear)
+	}
+
+	if o.maxLoad > 0 {
+		if err := res.MaxLoad(o.maxLoad); err != nil {
 			return nil, err
 		}
 	}
 
-	if cfg.Size > 0 {
-		res.Reserve(cfg.Size)
+	if o.capacity > 0 {
+		res.Reserve(o.capacity)
 	}
 
+	sort(o.sortKeys)
+
 	return res, nil
 }
+
+// TypeOf returns the `Type` of the hashmap or false, if it is not a
+// hashmap of this module. All `Compact` hashmaps are of type `Hopscotch`
+// and `Inline` hashmaps are of type `Unordered`.
+func TypeOf[K comparable, V any](m Map[K, V]) (Type, bool) {
+	switch m.(type) {
+	case *hopscotch.Hopscotch[K, V],
+		*hopscotch.Compact[K, V, uint8],
+		*hopscotch.Compact[K, V, uint16],
+		*hopscotch.Compact[K, V, uint32],
+		*hopscotch.Compact[K, V, uint64]:
+		return Hopscotch, true
+	case *robin.RobinHood[K, V]:
+		return Robin, true
+	case *unordered.Unordered[K, V], *unordered.Inline[K, V]:
+		return Unordered, true
+	case *flat.Flat[K, V]:
+		return Flat, true
+	case *indexmap.IndexMap[K, V]:
+		return IndexMap, true
+	default:
+		return 0, false
+	}
+}
+
+// Copy returns a copy of the hashmap by the `Copy` function of the concrete type.
+// It panics, if `m` is not a hashmap of this module.
+func Copy[K comparable, V any](m Map[K, V]) Map[K, V] {
+	switch c := m.(type) {
+	case *hopscotch.Hopscotch[K, V]:
+		return c.Copy()
+	case *hopscotch.Compact[K, V, uint8]:
+		return c.Copy()
+	case *hopscotch.Compact[K, V, uint16]:
+		return c.Copy()
+	case *hopscotch.Compact[K, V, uint32]:
+		return c.Copy()
+	case *hopscotch.Compact[K, V, uint64]:
+		return c.Copy()
+	case *robin.RobinHood[K, V]:
+		return c.Copy()
+	case *unordered.Unordered[K, V]:
+		return c.Copy()
+	case *unordered.Inline[K, V]:
+		return c.Copy()
+	case *flat.Flat[K, V]:
+		return c.Copy()
+	case *indexmap.IndexMap[K, V]:
+		return c.Copy()
+	default:
+		panic(fmt.Sprintf("unsupported hashmap type %T", m))
+	}
+}
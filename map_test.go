@@ -1,14 +1,24 @@
 package hashmaps_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"maps"
 	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/EinfachAndy/hashmaps"
+	"github.com/EinfachAndy/hashmaps/custom"
 	"github.com/EinfachAndy/hashmaps/flat"
+	"github.com/EinfachAndy/hashmaps/hamt"
+	"github.com/EinfachAndy/hashmaps/hashmaptest"
 	"github.com/EinfachAndy/hashmaps/hopscotch"
+	"github.com/EinfachAndy/hashmaps/indexmap"
 	"github.com/EinfachAndy/hashmaps/robin"
 	"github.com/EinfachAndy/hashmaps/shared"
 	"github.com/EinfachAndy/hashmaps/unordered"
@@ -28,124 +38,95 @@ func randString(n int) string {
 	return string(b)
 }
 
-func setupMaps[K comparable, V comparable]() []hashmaps.HashMap[K, V] {
-	return []hashmaps.HashMap[K, V]{
-		*hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+func setupMaps[K comparable, V comparable]() []hashmaps.Map[K, V] {
+	return []hashmaps.Map[K, V]{
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
 			Type:    hashmaps.Hopscotch,
 			MaxLoad: 0.95,
 		}),
-		*hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
 			Type:    hashmaps.Flat,
 			MaxLoad: 0.5,
 		}),
-		*hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
 			Type: hashmaps.Unordered,
 		}),
-		*hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
 			Type:    hashmaps.Robin,
 			MaxLoad: 0.90,
 		}),
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+			Type:    hashmaps.Hopscotch,
+			MaxLoad: 0.95,
+			Layout:  shared.SoA,
+		}),
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+			Type:    hashmaps.Flat,
+			MaxLoad: 0.5,
+			Layout:  shared.SoA,
+		}),
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+			Type:    hashmaps.Robin,
+			MaxLoad: 0.90,
+			Layout:  shared.SoA,
+		}),
+		hashmaps.MustNewHashMap(hashmaps.Config[K, V]{
+			Type:    hashmaps.IndexMap,
+			MaxLoad: 0.90,
+		}),
 	}
 }
 
-func checkeq[K comparable, V comparable](
-	t *testing.T,
-	cm *hashmaps.HashMap[K, V],
-	get func(k K) (V, bool),
-) {
-	cm.Each(func(key K, val V) bool {
-		ov, ok := get(key)
-		assert.True(t, ok, "key %v should exist", key)
-		assert.Equal(t, val, ov, "value mismatch: %v != %v", val, ov)
-
-		v, found := cm.Get(key)
-		assert.True(t, found, "double check failed for key %v", key)
-		assert.Equal(t, v, val, "double check failed for value %v", v)
-		return false
-	})
+// fuzzConfig is a hashmap type of `setupMaps` for the conformance harness.
+type fuzzConfig struct {
+	name string
+	typ  hashmaps.Type
+	opts []hashmaps.Option
 }
 
-func fuzzLoop[K comparable](t *testing.T, n int, getRandKey func(int) K) {
-	maps := setupMaps[K, K]()
-
-	for _, m := range maps {
-		stdm := make(map[K]K)
-
-		for i := 0; i < n; i++ {
-			var (
-				key = getRandKey(n / 10)
-				val = key
-				op  = rand.Intn(4)
-			)
-
-			switch op {
-			case 0:
-				var (
-					v1, ok1 = m.Get(key)
-					v2, ok2 = stdm[key]
-				)
-
-				assert.Equal(t, ok1, ok2, "lookup wrong state")
-				assert.Equal(t, v1, v2, "lookup values are different")
-			case 1:
-				// prioritize insert operation
-				fallthrough
-			case 2:
-				_, wasIn := stdm[key]
-				stdm[key] = val
-				isNew := m.Put(key, val)
-				assert.NotEqual(t, isNew, wasIn, "Put returned wrong state")
-
-				v, found := m.Get(key)
-				assert.True(t, found, "lookup failed after insert for key %d", key)
-				assert.Equal(t, val, v, "values are not equal %d != %d", v, val)
-			case 3:
-				var del K
-
-				if len(stdm) == 0 {
-					break
-				}
-
-				for k := range stdm {
-					del = k
-					break
-				}
-
-				delete(stdm, del)
-
-				_, found := m.Get(del)
-				assert.True(t, found, "lookup failed for key %d", key)
-				assert.True(t, m.Remove(del))
-
-				_, found = m.Get(del)
-				assert.False(t, found, "key %d was not removed", key)
-			}
-
-			assert.Equal(t, len(stdm), m.Size(), "len of hashmaps.are not equal %d != %d", len(stdm), m.Size())
-
-			checkeq(t, &m, func(k K) (K, bool) {
-				v, ok := stdm[k]
-				return v, ok
-			})
-		}
-		t.Log("size:", m.Size(), "Load", m.Load())
+func setupFuzzConfigs() []fuzzConfig {
+	return []fuzzConfig{
+		{"Hopscotch", hashmaps.Hopscotch, []hashmaps.Option{hashmaps.WithMaxLoad(0.95)}},
+		{"Flat", hashmaps.Flat, []hashmaps.Option{hashmaps.WithMaxLoad(0.5)}},
+		{"Unordered", hashmaps.Unordered, nil},
+		{"Robin", hashmaps.Robin, []hashmaps.Option{hashmaps.WithMaxLoad(0.90)}},
+		{"Hopscotch/SoA", hashmaps.Hopscotch, []hashmaps.Option{hashmaps.WithMaxLoad(0.95), hashmaps.WithLayout(shared.SoA)}},
+		{"Flat/SoA", hashmaps.Flat, []hashmaps.Option{hashmaps.WithMaxLoad(0.5), hashmaps.WithLayout(shared.SoA)}},
+		{"Robin/SoA", hashmaps.Robin, []hashmaps.Option{hashmaps.WithMaxLoad(0.90), hashmaps.WithLayout(shared.SoA)}},
+		{"IndexMap", hashmaps.IndexMap, []hashmaps.Option{hashmaps.WithMaxLoad(0.90)}},
 	}
 }
 
 func TestFuzzInt(t *testing.T) {
 	t.Parallel()
 
-	randInt := func(nLoops int) uint64 {
-		return uint64(rand.Intn(nLoops/10)) + 1
+	for _, c := range setupFuzzConfigs() {
+		t.Run(c.name, func(t *testing.T) {
+			hashmaptest.Run(t, hashmaptest.Config[uint64, uint64]{
+				New:   hashmaptest.Builtin[uint64, uint64](c.typ, c.opts...),
+				Copy:  hashmaps.Copy[uint64, uint64],
+				Key:   func(i int) uint64 { return uint64(i) + 1 },
+				Value: func(i int) uint64 { return uint64(i) },
+				Ops:   nLoops,
+			})
+		})
 	}
-
-	fuzzLoop(t, nLoops, randInt)
 }
 
 func TestFuzzString(t *testing.T) {
 	t.Parallel()
 
-	fuzzLoop(t, nLoops/4, randString)
+	for _, c := range setupFuzzConfigs() {
+		t.Run(c.name, func(t *testing.T) {
+			hashmaptest.Run(t, hashmaptest.Config[string, string]{
+				New:   hashmaptest.Builtin[string, string](c.typ, c.opts...),
+				Copy:  hashmaps.Copy[string, string],
+				Key:   func(i int) string { return "key" + strconv.Itoa(i) },
+				Value: strconv.Itoa,
+				Ops:   nLoops / 4,
+			})
+		})
+	}
 }
 
 func TestCopy(t *testing.T) {
@@ -157,8 +138,7 @@ func TestCopy(t *testing.T) {
 
 	cpy := orig.Copy()
 
-	c := hashmaps.HashMap[uint64, uint32]{Get: cpy.Get, Each: cpy.Each}
-	checkeq(t, &c, orig.Get)
+	hashmaptest.Verify(t, cpy, hashmaps.ToMap(orig))
 
 	cpy.Put(0, 42)
 
@@ -179,8 +159,7 @@ func TestCopyFlat(t *testing.T) {
 
 	cpy := orig.Copy()
 
-	c := hashmaps.HashMap[uint64, uint32]{Get: cpy.Get, Each: cpy.Each}
-	checkeq(t, &c, orig.Get)
+	hashmaptest.Verify(t, cpy, hashmaps.ToMap(orig))
 
 	cpy.Put(0, 42)
 
@@ -201,8 +180,7 @@ func TestCopyHopscotch(t *testing.T) {
 
 	cpy := orig.Copy()
 
-	c := hashmaps.HashMap[uint64, uint32]{Get: cpy.Get, Each: cpy.Each}
-	checkeq(t, &c, orig.Get)
+	hashmaptest.Verify(t, cpy, hashmaps.ToMap(orig))
 
 	cpy.Put(0, 42)
 
@@ -223,8 +201,7 @@ func TestCopyUnordered(t *testing.T) {
 
 	cpy := orig.Copy()
 
-	c := hashmaps.HashMap[uint64, uint32]{Get: cpy.Get, Each: cpy.Each}
-	checkeq(t, &c, orig.Get)
+	hashmaptest.Verify(t, cpy, hashmaps.ToMap(orig))
 
 	cpy.Put(0, 42)
 
@@ -347,21 +324,21 @@ func TestComplexKeyType(t *testing.T) {
 		hasher = func(d dummy) uintptr {
 			return 0
 		}
-		maps = []hashmaps.HashMap[dummy, string]{
-			*hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
+		maps = []hashmaps.Map[dummy, string]{
+			hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
 				Type:   hashmaps.Flat,
 				Hasher: hasher,
 				Empty:  dummy{},
 			}),
-			*hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
+			hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
 				Type:   hashmaps.Robin,
 				Hasher: hasher,
 			}),
-			*hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
+			hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
 				Type:   hashmaps.Hopscotch,
 				Hasher: hasher,
 			}),
-			*hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
+			hashmaps.MustNewHashMap(hashmaps.Config[dummy, string]{
 				Type:   hashmaps.Unordered,
 				Hasher: hasher,
 			}),
@@ -432,3 +409,1370 @@ func TestIteratorEarlyTermination(t *testing.T) {
 		assert.Equal(t, 3, count)
 	}
 }
+
+type mutableMap interface {
+	hashmaps.Map[int, int]
+	hashmaps.Mutable[int, int]
+}
+
+func TestEachMut(t *testing.T) {
+	t.Parallel()
+
+	constant := func(int) uintptr { return 0 }
+
+	soa := robin.New[int, int]()
+	_ = soa.Layout(shared.SoA)
+
+	// the constant hashers cover the overflow list and the trees
+	overflow := hopscotch.NewCompactWithHasher[int, int, uint8](constant)
+	overflow.OverflowSize(100)
+
+	maps := []mutableMap{
+		robin.New[int, int](),
+		soa,
+		flat.New[int, int](),
+		hopscotch.New[int, int](),
+		overflow,
+		unordered.New[int, int](),
+		unordered.NewWithHasher[int, int](constant),
+		unordered.NewInline[int, int](),
+	}
+
+	for _, m := range maps {
+		const n = 50
+		for i := 1; i <= n; i++ {
+			assert.True(t, m.Put(i, i))
+		}
+
+		count := 0
+
+		m.EachMut(func(key int, val *int) bool {
+			assert.Equal(t, key, *val)
+			*val *= 2
+			count++
+
+			return false
+		})
+		assert.Equal(t, n, count)
+
+		for key, val := range hashmaps.AllMut(m) {
+			assert.Equal(t, 2*key, *val)
+
+			if key == 1 {
+				*val++
+				break
+			}
+		}
+
+		for i := 1; i <= n; i++ {
+			v, ok := m.Get(i)
+			assert.True(t, ok)
+
+			if i == 1 {
+				assert.Equal(t, 3, v)
+			} else {
+				assert.Equal(t, 2*i, v)
+			}
+		}
+
+		// structural changes are rejected
+		for _, change := range []func(){
+			func() { m.Put(n+1, 0) },
+			func() { m.Put(1, 0) },
+			func() { m.Remove(1) },
+			func() { m.Clear() },
+			func() { m.Reserve(1000) },
+		} {
+			assert.PanicsWithValue(t, shared.ErrIterating, func() {
+				m.EachMut(func(int, *int) bool {
+					change()
+					return true
+				})
+			})
+		}
+
+		// the hashmap is usable after a panic
+		assert.Equal(t, n, m.Size())
+		assert.True(t, m.Put(n+1, 0))
+		assert.True(t, m.Remove(n+1))
+	}
+
+	// the snapshots keep the old values
+	for _, sm := range setupSnapshotMaps() {
+		for i := 1; i <= 2000; i++ {
+			sm.m.Put(i, i)
+		}
+
+		view, release := sm.snapshot()
+
+		sm.m.(hashmaps.Mutable[int, int]).EachMut(func(_ int, val *int) bool {
+			*val = -*val
+			return false
+		})
+
+		for i := 1; i <= 2000; i++ {
+			v, _ := view.Get(i)
+			assert.Equal(t, i, v)
+
+			v, _ = sm.m.Get(i)
+			assert.Equal(t, -i, v)
+		}
+
+		release()
+	}
+}
+
+func TestLayout(t *testing.T) {
+	t.Parallel()
+
+	type bigValue [32]uint32
+
+	var (
+		r = robin.New[int, bigValue]()
+		f = flat.New[int, bigValue]()
+		h = hopscotch.New[int, bigValue]()
+	)
+
+	maps := []hashmaps.Map[int, bigValue]{r, f, h}
+	layouts := []func(shared.Layout) error{r.Layout, f.Layout, h.Layout}
+
+	for i, m := range maps {
+		for k := 1; k <= 100; k++ {
+			m.Put(k, bigValue{uint32(k)})
+		}
+
+		// switching the layout rebuilds the hashmap
+		for _, l := range []shared.Layout{shared.AoS, shared.SoA, shared.AutoLayout} {
+			assert.NoError(t, layouts[i](l))
+			assert.Equal(t, 100, m.Size())
+
+			for k := 1; k <= 100; k++ {
+				v, found := m.Get(k)
+				assert.True(t, found)
+				assert.Equal(t, bigValue{uint32(k)}, v)
+			}
+		}
+
+		assert.Error(t, layouts[i](shared.Layout(42)))
+	}
+}
+
+func TestLazyClear(t *testing.T) {
+	t.Parallel()
+
+	type lazyMap interface {
+		hashmaps.Map[int, int]
+		LazyClear(on bool)
+	}
+
+	// a weak hasher fills the overflow list of the hopscotch hashmap
+	compact := hopscotch.NewCompactWithHasher[int, int, uint8](func(k int) uintptr { return uintptr(k/8) * 16 })
+	compact.OverflowSize(100)
+
+	maps := []lazyMap{compact}
+
+	for _, layout := range []shared.Layout{shared.AoS, shared.SoA} {
+		r := robin.New[int, int]()
+		f := flat.New[int, int]()
+		h := hopscotch.New[int, int]()
+
+		_ = r.Layout(layout)
+		_ = f.Layout(layout)
+		_ = h.Layout(layout)
+
+		maps = append(maps, r, f, h)
+	}
+
+	for _, m := range maps {
+		m.LazyClear(true)
+
+		// the generation counter wraps around
+		for round := 0; round < 600; round++ {
+			var (
+				stdm = make(map[int]int)
+				n    = rand.Intn(200)
+			)
+
+			for i := 0; i < n; i++ {
+				k := 1 + rand.Intn(300)
+				stdm[k] = round
+				m.Put(k, round)
+
+				if i%3 == 0 {
+					delete(stdm, k/2+1)
+					m.Remove(k/2 + 1)
+				}
+			}
+
+			assert.Equal(t, len(stdm), m.Size())
+			hashmaptest.Verify(t, m, stdm)
+
+			if round == 500 {
+				// the stale buckets are wiped
+				m.LazyClear(false)
+				hashmaptest.Verify(t, m, stdm)
+				m.LazyClear(true)
+			}
+
+			m.Clear()
+			assert.Zero(t, m.Size())
+
+			for k := 1; k <= 300; k++ {
+				_, found := m.Get(k)
+				assert.False(t, found)
+			}
+
+			m.Each(func(int, int) bool {
+				assert.Fail(t, "hashmap is not empty")
+				return true
+			})
+		}
+	}
+
+	m := hashmaps.MustNewHashMap(hashmaps.Config[int, int]{Type: hashmaps.Robin, LazyClear: true})
+	assert.True(t, m.Put(1, 1))
+	m.Clear()
+	assert.True(t, m.Put(1, 1))
+}
+
+func TestHopscotchCompact(t *testing.T) {
+	t.Parallel()
+
+	// a weak hasher, where always 8 keys share the same hash value
+	weak := func(k int) uintptr {
+		return uintptr(k/8) * 16
+	}
+
+	// 2 keys per hash value do not fit into the neighborhood of 6 buckets
+	m8 := hopscotch.NewCompactWithHasher[int, int, uint8](weak)
+	assert.Error(t, m8.NeighborhoodSize(0))
+	assert.Error(t, m8.NeighborhoodSize(7))
+	assert.NoError(t, m8.NeighborhoodSize(2))
+	m8.OverflowSize(32)
+
+	m16 := hopscotch.NewCompactWithHasher[int, int, uint16](weak)
+	m16.OverflowSize(0)
+
+	m32 := hopscotch.NewCompactWithHasher[int, int, uint32](weak)
+	m32.OverflowSize(100)
+
+	maps := []hashmaps.Map[int, int]{m8, m16, m32}
+
+	for _, m := range maps {
+		stdm := make(map[int]int)
+
+		for i := 0; i < 5000; i++ {
+			key := rand.Intn(64)
+
+			if rand.Intn(3) == 0 {
+				_, wasIn := stdm[key]
+				delete(stdm, key)
+				assert.Equal(t, wasIn, m.Remove(key))
+			} else {
+				_, wasIn := stdm[key]
+				stdm[key] = i
+				assert.Equal(t, !wasIn, m.Put(key, i))
+			}
+
+			assert.Equal(t, len(stdm), m.Size())
+		}
+
+		hashmaptest.Verify(t, m, stdm)
+
+		for k, v := range stdm {
+			got, found := m.Get(k)
+			assert.True(t, found)
+			assert.Equal(t, v, got)
+		}
+	}
+}
+
+func TestHopscotchCollisions(t *testing.T) {
+	t.Parallel()
+
+	// all keys share the hash value, so growing can not separate them
+	constant := func(int) uintptr { return 0 }
+
+	m8 := hopscotch.NewCompactWithHasher[int, int, uint8](constant)
+
+	disabled := hopscotch.NewCompactWithHasher[int, int, uint16](constant)
+	disabled.OverflowSize(0)
+
+	soa := hopscotch.NewWithHasher[int, int](constant)
+	_ = soa.Layout(shared.SoA)
+
+	maps := []hashmaps.Map[int, int]{m8, disabled, soa}
+
+	// more keys than the neighborhood of 6 buckets and the overflow list hold
+	const n = 2 * (6 + hopscotch.DefaultOverflowSize)
+
+	for _, m := range maps {
+		for i := 0; i < n; i++ {
+			assert.True(t, m.Put(i, i))
+		}
+
+		// the hashmap grows only by its load
+		assert.Equal(t, n, m.Size())
+		assert.Greater(t, m.Load(), float32(0.2))
+
+		for i := 0; i < n; i++ {
+			v, found := m.Get(i)
+			assert.True(t, found)
+			assert.Equal(t, i, v)
+		}
+
+		for i := 0; i < n; i += 2 {
+			assert.True(t, m.Remove(i))
+		}
+
+		for i := 0; i < n; i++ {
+			_, found := m.Get(i)
+			assert.Equal(t, i%2 == 1, found)
+		}
+	}
+}
+
+func TestUnorderedArena(t *testing.T) {
+	t.Parallel()
+
+	var (
+		m    = unordered.New[int, int]()
+		ptrs = make(map[int]*int)
+	)
+
+	for i := 0; i < 1000; i++ {
+		v, isNew := m.Insert(i)
+		assert.True(t, isNew)
+		*v = i
+		ptrs[i] = v
+	}
+
+	// removed nodes are reused
+	for i := 0; i < 1000; i += 2 {
+		assert.True(t, m.Remove(i))
+		delete(ptrs, i)
+	}
+
+	for i := 1000; i < 1500; i++ {
+		assert.True(t, m.Put(i, i))
+	}
+
+	// pointers of the remaining keys are stable
+	for k, ptr := range ptrs {
+		assert.Same(t, ptr, m.Lookup(k))
+		assert.Equal(t, k, *ptr)
+	}
+
+	m.Defragment()
+	assert.Equal(t, 1000, m.Size())
+
+	for i := 1; i < 1500; i++ {
+		v, found := m.Get(i)
+		assert.Equal(t, i%2 == 1 || i >= 1000, found)
+
+		if found {
+			assert.Equal(t, i, v)
+		}
+	}
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, m.Put(i, i))
+	}
+
+	cpy := m.Copy()
+	assert.Equal(t, 100, cpy.Size())
+}
+
+// structKey is a key type, which is not ordered.
+type structKey struct {
+	a int
+	b string
+}
+
+// structKeys adapts a hashmap with `structKey` keys to int keys.
+type structKeys struct {
+	*unordered.Unordered[structKey, int]
+}
+
+func (m structKeys) Get(k int) (int, bool) { return m.Unordered.Get(structKey{a: k}) }
+func (m structKeys) Put(k int, v int) bool { return m.Unordered.Put(structKey{a: k}, v) }
+func (m structKeys) Remove(k int) bool     { return m.Unordered.Remove(structKey{a: k}) }
+func (m structKeys) Each(fn func(k, v int) bool) {
+	m.Unordered.Each(func(k structKey, v int) bool { return fn(k.a, v) })
+}
+
+func TestUnorderedTreeify(t *testing.T) {
+	t.Parallel()
+
+	// all keys collide in one bucket
+	constant := unordered.NewWithHasher[int, int](func(int) uintptr { return 0 })
+
+	maps := []hashmaps.Map[int, int]{
+		constant,
+		// not ordered keys, where 8 keys share the same hash value
+		structKeys{unordered.NewWithHasher[structKey, int](func(k structKey) uintptr {
+			return uintptr(k.a/8) * 4096
+		})},
+	}
+
+	for _, m := range maps {
+		stdm := make(map[int]int)
+
+		for i := 0; i < 20000; i++ {
+			k := rand.Intn(500)
+
+			switch rand.Intn(4) {
+			case 0:
+				_, wasIn := stdm[k]
+				delete(stdm, k)
+				assert.Equal(t, wasIn, m.Remove(k))
+			case 1:
+				v1, ok1 := m.Get(k)
+				v2, ok2 := stdm[k]
+				assert.Equal(t, ok2, ok1)
+				assert.Equal(t, v2, v1)
+			default:
+				_, wasIn := stdm[k]
+				stdm[k] = i
+				assert.Equal(t, !wasIn, m.Put(k, i))
+			}
+
+			assert.Equal(t, len(stdm), m.Size())
+		}
+
+		count := 0
+		m.Each(func(_, _ int) bool {
+			count++
+			return false
+		})
+		assert.Equal(t, len(stdm), count)
+		hashmaptest.Verify(t, m, stdm)
+	}
+
+	constant.Defragment()
+	cpy := constant.Copy()
+	assert.Equal(t, constant.Size(), cpy.Size())
+
+	for k := 0; k < 500; k++ {
+		v1, ok1 := constant.Get(k)
+		v2, ok2 := cpy.Get(k)
+		assert.Equal(t, ok1, ok2)
+		assert.Equal(t, v1, v2)
+	}
+}
+
+func TestUnorderedInline(t *testing.T) {
+	t.Parallel()
+
+	var (
+		m    = unordered.NewInlineWithHasher[int, int](func(k int) uintptr { return uintptr(k % 50) })
+		stdm = make(map[int]int)
+	)
+
+	for i := 0; i < 20000; i++ {
+		k := rand.Intn(500)
+
+		switch rand.Intn(4) {
+		case 0:
+			_, wasIn := stdm[k]
+			delete(stdm, k)
+			assert.Equal(t, wasIn, m.Remove(k))
+		case 1:
+			v1, ok1 := m.Get(k)
+			v2, ok2 := stdm[k]
+			assert.Equal(t, ok2, ok1)
+			assert.Equal(t, v2, v1)
+		default:
+			_, wasIn := stdm[k]
+			stdm[k] = i
+			assert.Equal(t, !wasIn, m.Put(k, i))
+		}
+
+		assert.Equal(t, len(stdm), m.Size())
+	}
+
+	cpy := m.Copy()
+	hashmaptest.Verify(t, cpy, stdm)
+
+	for k, v := range stdm {
+		ptr := m.Lookup(k)
+		assert.NotNil(t, ptr)
+		assert.Equal(t, v, *ptr)
+	}
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+	assert.Nil(t, m.Lookup(1))
+}
+
+func TestIndexMap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		m    = indexmap.New[int, int]()
+		stdm = make(map[int]int)
+	)
+
+	for i := 0; i < 20000; i++ {
+		k := rand.Intn(500)
+
+		switch rand.Intn(5) {
+		case 0:
+			_, wasIn := stdm[k]
+			delete(stdm, k)
+			assert.Equal(t, wasIn, m.Remove(k))
+		case 1:
+			_, wasIn := stdm[k]
+			delete(stdm, k)
+			assert.Equal(t, wasIn, m.ShiftRemove(k))
+		case 2:
+			v1, ok1 := m.Get(k)
+			v2, ok2 := stdm[k]
+			assert.Equal(t, ok2, ok1)
+			assert.Equal(t, v2, v1)
+		default:
+			_, wasIn := stdm[k]
+			stdm[k] = i
+			assert.Equal(t, !wasIn, m.Put(k, i))
+		}
+
+		assert.Equal(t, len(stdm), m.Size())
+	}
+
+	// positional access is consistent with the index
+	for i := 0; i < m.Size(); i++ {
+		k, v, ok := m.GetIndex(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, m.IndexOf(k))
+		assert.Equal(t, stdm[k], v)
+	}
+
+	_, _, ok := m.GetIndex(m.Size())
+	assert.False(t, ok)
+	assert.Equal(t, -1, m.IndexOf(-1))
+
+	cpy := m.Copy()
+	hashmaptest.Verify(t, cpy, stdm)
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+
+	// insertion order
+	for i := 0; i < 100; i++ {
+		m.Put(i*7, i)
+	}
+
+	assert.True(t, m.ShiftRemove(0))
+	assert.True(t, m.Remove(7))
+
+	expected := []int{693}
+	for i := 2; i < 99; i++ {
+		expected = append(expected, i*7)
+	}
+
+	keys := make([]int, 0, m.Size())
+	m.Each(func(k, _ int) bool {
+		keys = append(keys, k)
+		return false
+	})
+	assert.Equal(t, expected, keys)
+}
+
+func TestHAMT(t *testing.T) {
+	t.Parallel()
+
+	type version struct {
+		m    *hamt.Map[int, int]
+		stdm map[int]int
+	}
+
+	hashers := []shared.HashFn[int]{
+		shared.GetHasher[int](),
+		// full hash collisions
+		func(k int) uintptr { return uintptr(k % 64) },
+	}
+
+	for _, hasher := range hashers {
+		var (
+			m        = hamt.NewWithHasher[int, int](hasher)
+			stdm     = make(map[int]int)
+			versions []version
+		)
+
+		for i := 0; i < 20000; i++ {
+			k := rand.Intn(1000)
+
+			switch rand.Intn(3) {
+			case 0:
+				delete(stdm, k)
+				m = m.Without(k)
+			default:
+				stdm[k] = i
+				m = m.With(k, i)
+			}
+
+			assert.Equal(t, len(stdm), m.Size())
+
+			if i%1000 == 0 {
+				cpy := make(map[int]int, len(stdm))
+				for k, v := range stdm {
+					cpy[k] = v
+				}
+
+				versions = append(versions, version{m: m, stdm: cpy})
+			}
+		}
+
+		// the old versions are unchanged
+		for _, ver := range versions {
+			assert.Equal(t, len(ver.stdm), ver.m.Size())
+			hashmaptest.Verify(t, ver.m, ver.stdm)
+		}
+
+		// a builder does not change the original hashmap
+		var (
+			b     = m.Builder()
+			bstdm = make(map[int]int, len(stdm))
+		)
+
+		for k, v := range stdm {
+			bstdm[k] = v
+		}
+
+		for i := 0; i < 1000; i++ {
+			k := rand.Intn(2000)
+			_, wasIn := bstdm[k]
+
+			if rand.Intn(2) == 0 {
+				delete(bstdm, k)
+				assert.Equal(t, wasIn, b.Remove(k))
+			} else {
+				bstdm[k] = i
+				assert.Equal(t, !wasIn, b.Put(k, i))
+			}
+		}
+
+		built := b.Map()
+		assert.Equal(t, b.Size(), built.Size())
+		b.Put(-1, -1)
+
+		_, found := built.Get(-1)
+		assert.False(t, found)
+		assert.Equal(t, len(stdm), m.Size())
+		hashmaptest.Verify(t, m, stdm)
+
+		assert.Equal(t, len(bstdm), built.Size())
+		hashmaptest.Verify(t, built, bstdm)
+	}
+}
+
+func TestHAMTAllocs(t *testing.T) {
+	// the first entry allocates only the hashmap, the root node and its slots
+	empty := hamt.New[int, int]()
+	assert.Equal(t, float64(3), testing.AllocsPerRun(100, func() {
+		empty.With(1, 1)
+	}))
+}
+
+type snapshotMap struct {
+	m        hashmaps.Map[int, int]
+	snapshot func() (view hashmaptest.View[int, int], release func())
+}
+
+func setupSnapshotMaps() []snapshotMap {
+	var res []snapshotMap
+
+	for _, layout := range []shared.Layout{shared.AoS, shared.SoA} {
+		r := robin.New[int, int]()
+		f := flat.New[int, int]()
+		h := hopscotch.New[int, int]()
+
+		_ = r.Layout(layout)
+		_ = f.Layout(layout)
+		_ = h.Layout(layout)
+
+		res = append(res,
+			snapshotMap{
+				m: r,
+				snapshot: func() (hashmaptest.View[int, int], func()) {
+					s := r.Snapshot()
+					return s, s.Release
+				},
+			},
+			snapshotMap{
+				m: f,
+				snapshot: func() (hashmaptest.View[int, int], func()) {
+					s := f.Snapshot()
+					return s, s.Release
+				},
+			},
+			snapshotMap{
+				m: h,
+				snapshot: func() (hashmaptest.View[int, int], func()) {
+					s := h.Snapshot()
+					return s, s.Release
+				},
+			},
+		)
+	}
+
+	return res
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	type version struct {
+		view    hashmaptest.View[int, int]
+		release func()
+		stdm    map[int]int
+	}
+
+	checkVersion := func(ver version) {
+		assert.Equal(t, len(ver.stdm), ver.view.Size())
+		hashmaptest.Verify(t, ver.view, ver.stdm)
+
+		for k, v := range ver.stdm {
+			got, ok := ver.view.Get(k)
+			assert.True(t, ok)
+			assert.Equal(t, v, got)
+		}
+	}
+
+	for _, sm := range setupSnapshotMaps() {
+		var (
+			stdm     = make(map[int]int)
+			versions []version
+		)
+
+		for i := 0; i < 30000; i++ {
+			// the key range grows, so the hashmap is resized
+			k := 1 + rand.Intn(1+i/4)
+
+			switch rand.Intn(3) {
+			case 0:
+				delete(stdm, k)
+				sm.m.Remove(k)
+			default:
+				stdm[k] = i
+				sm.m.Put(k, i)
+			}
+
+			switch {
+			case i == 20000:
+				stdm = make(map[int]int)
+				sm.m.Clear()
+			case i%2000 == 0:
+				cpy := make(map[int]int, len(stdm))
+				for k, v := range stdm {
+					cpy[k] = v
+				}
+
+				view, release := sm.snapshot()
+				versions = append(versions, version{view: view, release: release, stdm: cpy})
+			case i%3000 == 0:
+				// release the oldest snapshot
+				checkVersion(versions[0])
+				versions[0].release()
+				versions = versions[1:]
+			}
+		}
+
+		assert.Equal(t, len(stdm), sm.m.Size())
+
+		for _, ver := range versions {
+			checkVersion(ver)
+			ver.release()
+		}
+	}
+}
+
+func TestSnapshotConcurrentRead(t *testing.T) {
+	t.Parallel()
+
+	for _, sm := range setupSnapshotMaps() {
+		for i := 1; i <= 5000; i++ {
+			sm.m.Put(i, i)
+		}
+
+		var (
+			view, release = sm.snapshot()
+			wg            sync.WaitGroup
+		)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 1; i <= 5000; i++ {
+				v, ok := view.Get(i)
+				assert.True(t, ok)
+				assert.Equal(t, i, v)
+			}
+
+			n := 0
+			view.Each(func(k, v int) bool {
+				assert.Equal(t, k, v)
+				n++
+
+				return false
+			})
+			assert.Equal(t, 5000, n)
+		}()
+
+		for i := 1; i <= 5000; i++ {
+			if i%2 == 0 {
+				sm.m.Remove(i)
+			} else {
+				sm.m.Put(i, -i)
+			}
+		}
+
+		wg.Wait()
+		release()
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	collect := func(seq func(yield func(int) bool)) map[int]bool {
+		res := make(map[int]bool)
+		seq(func(k int) bool {
+			res[k] = true
+			return true
+		})
+
+		return res
+	}
+
+	eq := func(a, b int) bool { return a == b }
+
+	for _, a := range setupMaps[int, int]() {
+		b := hashmaps.MustNewHashMap(hashmaps.Config[int, int]{Type: hashmaps.Flat, Empty: -1})
+
+		for i := 1; i <= 1000; i++ {
+			a.Put(i, i)
+			b.Put(i+100, i+100)
+		}
+
+		// change some values
+		for i := 200; i < 300; i++ {
+			b.Put(i, -i)
+		}
+
+		assert.False(t, hashmaps.Equal(a, b, eq))
+		assert.True(t, hashmaps.Equal(a, a, eq))
+
+		d := hashmaps.Diff(a, b)
+		added, removed, changed := collect(d.Added), collect(d.Removed), collect(d.Changed)
+
+		assert.Len(t, added, 100)
+		assert.Len(t, removed, 100)
+		assert.Len(t, changed, 100)
+
+		for i := 1; i <= 100; i++ {
+			assert.True(t, removed[i])
+			assert.True(t, added[i+1000])
+			assert.True(t, changed[i+199])
+		}
+
+		// early termination
+		n := 0
+		d.Changed(func(int) bool {
+			n++
+			return n < 10
+		})
+		assert.Equal(t, 10, n)
+
+		d.Apply(a)
+		assert.True(t, hashmaps.Equal(a, b, eq))
+
+		d = hashmaps.Diff(a, b)
+		assert.Empty(t, collect(d.Added))
+		assert.Empty(t, collect(d.Removed))
+		assert.Empty(t, collect(d.Changed))
+
+		// a size mismatch returns early
+		b.Remove(500)
+		assert.False(t, hashmaps.Equal(a, b, func(int, int) bool {
+			assert.Fail(t, "no comparison expected")
+			return true
+		}))
+	}
+}
+
+// textKey is encoded by `encoding.TextMarshaler` instead of its integer kind.
+type textKey int
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("key-%d", k)), nil
+}
+
+func (k *textKey) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "key-%d", (*int)(k))
+	return err
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	stdm := make(map[int]string)
+	for i := 1; i <= 100; i++ {
+		stdm[i] = randString(i % 10)
+	}
+
+	expected, err := json.Marshal(stdm)
+	assert.NoError(t, err)
+
+	for _, m := range setupMaps[int, string]() {
+		for k, v := range stdm {
+			m.Put(k, v)
+		}
+
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(expected), string(data))
+
+		m.Clear()
+		assert.NoError(t, json.Unmarshal(expected, m))
+		hashmaptest.Verify(t, m, stdm)
+		assert.NoError(t, json.Unmarshal([]byte("null"), m))
+		assert.Equal(t, len(stdm), m.Size())
+	}
+
+	// the sorted output matches the std hashmap
+	sorted := hashmaps.MustNewHashMap(hashmaps.Config[int, string]{Type: hashmaps.Robin, SortKeys: true})
+	r := robin.New[int, string]()
+	r.SortKeys(true)
+
+	for k, v := range stdm {
+		sorted.Put(k, v)
+		r.Put(k, v)
+	}
+
+	for _, m := range []any{sorted, r} {
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(data))
+	}
+
+	// nested values and keys of a `TextMarshaler`
+	u := unordered.New[textKey, []int]()
+	u.SortKeys(true)
+	u.Put(1, []int{1, 2})
+	u.Put(2, nil)
+	u.Put(10, []int{})
+
+	data, err := json.Marshal(u)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"key-1":[1,2],"key-10":[],"key-2":null}`, string(data))
+
+	idx := indexmap.New[textKey, []int]()
+	assert.NoError(t, json.Unmarshal(data, idx))
+	assert.Equal(t, 3, idx.Size())
+
+	key, val, _ := idx.GetIndex(1)
+	assert.Equal(t, textKey(10), key)
+	assert.Equal(t, []int{}, val)
+
+	// invalid keys
+	h := hopscotch.New[int8, int]()
+	assert.Error(t, json.Unmarshal([]byte(`{"300":1}`), h))
+	assert.Error(t, json.Unmarshal([]byte(`{"a":1}`), h))
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), h))
+
+	fm := flat.NewWithHasher[float64, int](-1, shared.GetHasher[float64]())
+	fm.Put(1.5, 1)
+	_, err = json.Marshal(fm)
+	assert.Error(t, err)
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	stdm := make(map[int]int)
+	for i := 1; i <= 1000; i++ {
+		stdm[i] = i * 2
+	}
+
+	for _, m := range setupMaps[int, int]() {
+		hashmaps.FromMap(m, stdm)
+		hashmaptest.Verify(t, m, stdm)
+		assert.Equal(t, stdm, hashmaps.ToMap(m))
+
+		keys := hashmaps.Keys(m)
+		values := hashmaps.Values(m)
+		assert.Len(t, keys, len(stdm))
+		assert.Len(t, values, len(stdm))
+
+		for i := range keys {
+			assert.Equal(t, stdm[keys[i]], values[i])
+		}
+
+		// early termination of the iterator
+		n := 0
+		for range hashmaps.All[int, int](m) {
+			if n++; n == 10 {
+				break
+			}
+		}
+		assert.Equal(t, 10, n)
+
+		r := hashmaps.Clone(robin.New[int, int](), m)
+		assert.Equal(t, stdm, hashmaps.ToMap[int, int](r))
+
+		m.Clear()
+		assert.Equal(t, len(stdm), hashmaps.Insert(m, hashmaps.All[int, int](r)))
+		assert.Equal(t, 0, hashmaps.Insert(m, hashmaps.All[int, int](r)))
+		hashmaptest.Verify(t, m, stdm)
+	}
+
+	c, err := hashmaps.Collect(hashmaps.Config[int, int]{Type: hashmaps.IndexMap}, maps.All(stdm))
+	assert.NoError(t, err)
+	hashmaptest.Verify(t, c, stdm)
+
+	// every backend implements the interfaces
+	for _, rw := range []hashmaps.ReadWriter[int, int]{
+		robin.New[int, int](),
+		flat.New[int, int](),
+		hopscotch.New[int, int](),
+		hopscotch.NewCompact[int, int, uint16](),
+		unordered.New[int, int](),
+		unordered.NewInline[int, int](),
+		indexmap.New[int, int](),
+	} {
+		assert.Equal(t, stdm, hashmaps.ToMap(hashmaps.FromMap(rw, stdm)))
+	}
+}
+
+func TestNewHashMap(t *testing.T) {
+	t.Parallel()
+
+	for _, typ := range []hashmaps.Type{hashmaps.Hopscotch, hashmaps.Robin, hashmaps.Unordered, hashmaps.Flat, hashmaps.IndexMap} {
+		m, err := hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: typ, Empty: -1})
+		assert.NoError(t, err)
+
+		got, ok := hashmaps.TypeOf(m)
+		assert.True(t, ok)
+		assert.Equal(t, typ, got)
+		assert.NotContains(t, typ.String(), "Type(")
+
+		for i := 1; i <= 100; i++ {
+			m.Put(i, i)
+		}
+
+		// the copy is independent and of the same type
+		cpy := hashmaps.Copy(m)
+		cpy.Put(101, 101)
+		assert.Equal(t, 100, m.Size())
+		assert.Equal(t, 101, cpy.Size())
+
+		got, ok = hashmaps.TypeOf(cpy)
+		assert.True(t, ok)
+		assert.Equal(t, typ, got)
+
+		// the deprecated facade
+		h := hashmaps.NewAdapter(m) //nolint:staticcheck
+		v, found := h.Get(50)
+		assert.True(t, found)
+		assert.Equal(t, 50, v)
+		assert.Equal(t, m.Size(), h.Size())
+	}
+
+	_, err := hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: 42})
+	assert.ErrorIs(t, err, shared.ErrOutOfRange)
+	assert.Equal(t, "Type(42)", hashmaps.Type(42).String())
+	assert.Panics(t, func() { hashmaps.MustNewHashMap(hashmaps.Config[int, int]{Type: -1}) })
+
+	compact := hopscotch.NewCompact[int, int, uint16]()
+	compact.Put(1, 1)
+
+	got, ok := hashmaps.TypeOf[int, int](compact)
+	assert.True(t, ok)
+	assert.Equal(t, hashmaps.Hopscotch, got)
+	assert.Equal(t, 1, hashmaps.Copy[int, int](compact).Size())
+
+	_, ok = hashmaps.TypeOf[int, int](structKeys{unordered.NewWithHasher[structKey, int](func(structKey) uintptr { return 0 })})
+	assert.False(t, ok)
+}
+
+func TestOptions(t *testing.T) {
+	t.Parallel()
+
+	m, err := hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: hashmaps.Flat},
+		hashmaps.WithEmptyKey(-1),
+		hashmaps.WithHasher(func(k int) uintptr { return uintptr(k) }),
+		hashmaps.WithMaxLoad(0.5),
+		hashmaps.WithCapacity(1000),
+		hashmaps.WithLayout(shared.SoA),
+	)
+	assert.NoError(t, err)
+	assert.True(t, m.Put(0, 1))
+	assert.InDelta(t, 1.0/2048, m.Load(), 0.0001)
+
+	h, err := hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: hashmaps.Hopscotch},
+		hashmaps.WithNeighborhoodSize(8),
+		hashmaps.WithOverflowSize(0),
+	)
+	assert.NoError(t, err)
+	assert.True(t, h.Put(1, 1))
+
+	r, err := hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: hashmaps.Robin},
+		hashmaps.WithSearchStrategy(robin.OrganPipeSearch),
+		hashmaps.WithSortKeys(true),
+	)
+	assert.NoError(t, err)
+	r.Put(2, 2)
+	r.Put(1, 1)
+
+	data, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"1":1,"2":2}`, string(data))
+
+	for _, tc := range []struct {
+		cfg  hashmaps.Config[int, int]
+		opt  hashmaps.Option
+		want error
+	}{
+		{hashmaps.Config[int, int]{MaxLoad: -1}, nil, shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{}, hashmaps.WithMaxLoad(0), shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{}, hashmaps.WithMaxLoad(1), shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{}, hashmaps.WithLayout(42), shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{}, hashmaps.WithNeighborhoodSize(0), shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{}, hashmaps.WithNeighborhoodSize(100), shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{}, hashmaps.WithHasher[int](nil), shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{Type: hashmaps.Robin}, hashmaps.WithSearchStrategy(42), shared.ErrOutOfRange},
+		{hashmaps.Config[int, int]{Type: hashmaps.Robin}, hashmaps.WithEmptyKey(-1), shared.ErrUnsupported},
+		{hashmaps.Config[int, int]{Type: hashmaps.Unordered}, hashmaps.WithLayout(shared.SoA), shared.ErrUnsupported},
+		{hashmaps.Config[int, int]{Type: hashmaps.Flat}, hashmaps.WithNeighborhoodSize(4), shared.ErrUnsupported},
+		{hashmaps.Config[int, int]{Type: hashmaps.IndexMap}, hashmaps.WithLazyClear(true), shared.ErrUnsupported},
+		{hashmaps.Config[int, int]{Type: hashmaps.IndexMap}, hashmaps.WithSearchStrategy(robin.LinearSearch), shared.ErrUnsupported},
+		{hashmaps.Config[int, int]{Type: hashmaps.Flat}, hashmaps.WithEmptyKey("x"), shared.ErrTypeMismatch},
+		{hashmaps.Config[int, int]{}, hashmaps.WithHasher(shared.GetHasher[string]()), shared.ErrTypeMismatch},
+	} {
+		var opts []hashmaps.Option
+		if tc.opt != nil {
+			opts = append(opts, tc.opt)
+		}
+
+		_, err := hashmaps.NewHashMap(tc.cfg, opts...)
+		assert.ErrorIs(t, err, tc.want)
+	}
+
+	// the chained hashmaps support load factors above 1
+	_, err = hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: hashmaps.Unordered, MaxLoad: 1.5})
+	assert.NoError(t, err)
+	// `Inline` has no factory type, but the same bound
+	assert.NoError(t, unordered.NewInline[int, int]().MaxLoad(1.5))
+
+	for _, typ := range []hashmaps.Type{hashmaps.Robin, hashmaps.Flat, hashmaps.Hopscotch} {
+		_, err = hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: typ, MaxLoad: 1.5})
+		assert.ErrorIs(t, err, shared.ErrOutOfRange, typ)
+	}
+
+	// the config fields of other types are ignored
+	_, err = hashmaps.NewHashMap(hashmaps.Config[int, int]{Type: hashmaps.Unordered, Empty: -1, Layout: shared.SoA, LazyClear: true})
+	assert.NoError(t, err)
+}
+
+func TestAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	for _, a := range shared.Algorithms() {
+		for _, typ := range []hashmaps.Type{hashmaps.Hopscotch, hashmaps.Robin, hashmaps.Unordered, hashmaps.Flat, hashmaps.IndexMap} {
+			m, err := hashmaps.NewHashMap(hashmaps.Config[string, int]{Type: typ, Algorithm: a, Seed: 42})
+			assert.NoError(t, err)
+
+			for i := 1; i <= 1000; i++ {
+				assert.True(t, m.Put(strconv.Itoa(i), i))
+			}
+
+			for i := 1; i <= 1000; i++ {
+				v, ok := m.Get(strconv.Itoa(i))
+				assert.True(t, ok)
+				assert.Equal(t, i, v)
+			}
+		}
+	}
+
+	m, err := hashmaps.NewHashMap(hashmaps.Config[uint64, int]{Type: hashmaps.Robin},
+		hashmaps.WithAlgorithmName("XXH3", 1))
+	assert.NoError(t, err)
+	assert.True(t, m.Put(1, 1))
+
+	// the hasher of the config takes precedence
+	s, err := hashmaps.NewHashMap(hashmaps.Config[structKey, int]{
+		Algorithm: shared.WyHash,
+		Hasher:    func(k structKey) uintptr { return uintptr(k.a) },
+	})
+	assert.NoError(t, err)
+	assert.True(t, s.Put(structKey{a: 1}, 1))
+
+	_, err = hashmaps.NewHashMap(hashmaps.Config[int, int]{}, hashmaps.WithAlgorithmName("md5", 0))
+	assert.ErrorIs(t, err, shared.ErrUnsupported)
+
+	_, err = hashmaps.NewHashMap(hashmaps.Config[int, int]{Algorithm: -1})
+	assert.ErrorIs(t, err, shared.ErrOutOfRange)
+
+	_, err = hashmaps.NewHashMap(hashmaps.Config[structKey, int]{}, hashmaps.WithAlgorithm(shared.WyHash, 0))
+	assert.ErrorIs(t, err, shared.ErrUnsupported)
+}
+
+type name string
+
+func TestBytes(t *testing.T) {
+	const n = 100
+
+	maps := setupMaps[string, int]()
+	maps = append(maps, hashmaps.MustNewHashMap(hashmaps.Config[string, int]{Algorithm: shared.WyHash, Seed: 1}))
+
+	for _, m := range maps {
+		keys := make([][]byte, n)
+
+		for i := range keys {
+			keys[i] = []byte(strconv.Itoa(i) + randString(i%20))
+			m.Put(string(keys[i]), i)
+		}
+
+		for _, key := range keys {
+			v1, ok1 := m.Get(string(key))
+			v2, ok2 := hashmaps.GetBytes(m, key)
+			assert.Equal(t, ok1, ok2)
+			assert.Equal(t, v1, v2)
+			assert.True(t, hashmaps.ContainsBytes(m, key))
+		}
+
+		missing := []byte("missing key")
+		assert.False(t, hashmaps.ContainsBytes(m, missing))
+		assert.False(t, hashmaps.RemoveBytes(m, missing))
+
+		assert.Zero(t, testing.AllocsPerRun(100, func() {
+			hashmaps.GetBytes(m, keys[0])
+			hashmaps.ContainsBytes(m, missing)
+			hashmaps.RemoveBytes(m, missing)
+		}))
+
+		i := 0
+		assert.Zero(t, testing.AllocsPerRun(n-1, func() {
+			assert.True(t, hashmaps.RemoveBytes(m, keys[i]))
+			i++
+		}))
+		assert.Zero(t, m.Size())
+	}
+
+	r := robin.New[name, int]()
+	r.Put("key", 1)
+
+	v, ok := hashmaps.GetBytes(r, []byte("key"))
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.True(t, hashmaps.RemoveBytes(r, []byte("key")))
+}
+
+func TestCustom(t *testing.T) {
+	t.Parallel()
+
+	var (
+		m    = custom.NewBytes[int]()
+		stdm = make(map[string]int)
+	)
+
+	m.Debug(true)
+
+	for i := 0; i < nLoops; i++ {
+		k := strconv.Itoa(rand.Intn(nLoops / 4))
+
+		switch rand.Intn(3) {
+		case 0:
+			_, found := stdm[k]
+			assert.Equal(t, found, m.Remove([]byte(k)))
+			delete(stdm, k)
+		default:
+			_, found := stdm[k]
+			assert.Equal(t, !found, m.Put([]byte(k), i))
+			stdm[k] = i
+		}
+
+		if i%100 == 0 {
+			assert.Equal(t, len(stdm), m.Size())
+
+			for k, v := range stdm {
+				got, found := m.Get([]byte(k))
+				assert.True(t, found)
+				assert.Equal(t, v, got)
+			}
+		}
+	}
+
+	cpy := m.Copy()
+	m.Clear()
+	assert.Zero(t, m.Size())
+	assert.Len(t, hashmaps.Keys(cpy), len(stdm))
+
+	for key, val := range hashmaps.All(cpy) {
+		assert.Equal(t, stdm[string(key)], val)
+	}
+
+	ids := custom.NewSlice[uint32, string]()
+	assert.True(t, ids.Put([]uint32{1, 2, 3}, "a"))
+	assert.True(t, ids.Put([]uint32{3, 2, 1}, "b"))
+	assert.True(t, ids.Put(nil, "c"))
+	assert.False(t, ids.Put([]uint32{}, "d"))
+
+	v, found := ids.Get([]uint32{1, 2, 3})
+	assert.True(t, found)
+	assert.Equal(t, "a", v)
+	assert.Equal(t, 3, ids.Size())
+
+	// case insensitive keys, which are hashed by the lower case
+	hasher := shared.GetHasher[string]()
+	fold := custom.New[string, int](func(k string) uintptr { return hasher(strings.ToLower(k)) }, strings.EqualFold)
+	fold.Debug(true)
+	assert.True(t, fold.Put("Key", 1))
+	assert.False(t, fold.Put("KEY", 2))
+
+	v2, found := fold.Get("key")
+	assert.True(t, found)
+	assert.Equal(t, 2, v2)
+
+	// the debug mode detects hashers, which do not match the equality,
+	// here the equal keys share the home bucket, but not the hash value
+	inconsistent := custom.New[string, int](func(k string) uintptr {
+		h := hasher(strings.ToLower(k))
+		if k != strings.ToLower(k) {
+			h ^= 1 << 24
+		}
+
+		return h
+	}, strings.EqualFold)
+	inconsistent.Debug(true)
+	inconsistent.Put("key", 1)
+
+	panicsWith := func(err error, fn func()) {
+		defer func() {
+			r, _ := recover().(error)
+			assert.ErrorIs(t, r, err)
+		}()
+
+		fn()
+	}
+
+	panicsWith(shared.ErrInconsistent, func() {
+		inconsistent.Get("KEY")
+	})
+	panicsWith(shared.ErrInconsistent, func() {
+		m := custom.New[string, int](func(string) uintptr { return uintptr(rand.Int()) }, strings.EqualFold)
+		m.Debug(true)
+		m.Put("key", 1)
+	})
+	panicsWith(shared.ErrInconsistent, func() {
+		m := custom.New[string, int](hasher, func(a, b string) bool { return false })
+		m.Debug(true)
+		m.Get("key")
+	})
+}
@@ -0,0 +1,241 @@
+package hashmaps
+
+import (
+	"fmt"
+
+	"github.com/EinfachAndy/hashmaps/robin"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// Option configures the hashmap of `NewHashMap`. The options are validated,
+// when they are applied, and the first error is returned by `NewHashMap`.
+type Option func(o *options) error
+
+type options struct {
+	hasher    any
+	algorithm shared.Algorithm
+	seed      uint64
+	empty     any
+	maxLoad   float32
+	capacity  uintptr
+	layout    shared.Layout
+	lazyClear bool
+	sortKeys  bool
+
+	neighborhoodSize uintptr
+	overflowSize     *uintptr
+	strategy         *robin.Strategy
+
+	// restricted lists the backend specific options
+	restricted []restriction
+}
+
+// restriction limits an option to some types of hashmaps.
+type restriction struct {
+	name  string
+	types []Type
+}
+
+func (o *options) restrict(name string, types ...Type) {
+	o.restricted = append(o.restricted, restriction{name: name, types: types})
+}
+
+// check returns ErrUnsupported, if an option is not supported by `t`.
+func (o *options) check(t Type) error {
+	for _, r := range o.restricted {
+		supported := false
+
+		for _, typ := range r.types {
+			supported = supported || typ == t
+		}
+
+		if !supported {
+			return fmt.Errorf("option %s for %v: %w", r.name, t, shared.ErrUnsupported)
+		}
+	}
+
+	return nil
+}
+
+// WithHasher sets the hash function. Must be configured for complex data
+// types or slices. The key type must match the hashmap, otherwise
+// `NewHashMap` returns ErrTypeMismatch.
+func WithHasher[K comparable](hasher shared.HashFn[K]) Option {
+	return func(o *options) error {
+		if hasher == nil {
+			return fmt.Errorf("hasher nil: %w", shared.ErrOutOfRange)
+		}
+
+		o.hasher = hasher
+		o.algorithm, o.seed = shared.Default, 0
+
+		return nil
+	}
+}
+
+// WithAlgorithm selects the hash function of the algorithm with the given seed,
+// see `shared.GetSeededHasher`. It replaces the hasher of a previous option.
+// Returns ErrOutOfRange if `a` is unknown.
+func WithAlgorithm(a shared.Algorithm, seed uint64) Option {
+	return func(o *options) error {
+		if a < shared.Default || a > shared.MapHash {
+			return fmt.Errorf("algorithm %d: %w", a, shared.ErrOutOfRange)
+		}
+
+		o.hasher = nil
+		o.algorithm, o.seed = a, seed
+
+		return nil
+	}
+}
+
+// WithAlgorithmName is the same as `WithAlgorithm`, but selects the algorithm by
+// its case insensitive name. Returns ErrUnsupported if the name is unknown.
+func WithAlgorithmName(name string, seed uint64) Option {
+	return func(o *options) error {
+		a, err := shared.ParseAlgorithm(name)
+		if err != nil {
+			return err
+		}
+
+		return WithAlgorithm(a, seed)(o)
+	}
+}
+
+// WithMaxLoad sets the load factor of the hashmap, see `Map.MaxLoad`.
+// Returns ErrOutOfRange if `lf` is less than or equal zero. The upper bound
+// depends on the type and is checked by the `MaxLoad` of the hashmap.
+func WithMaxLoad(lf float32) Option {
+	return func(o *options) error {
+		if lf <= 0.0 {
+			return fmt.Errorf("max load %f: %w", lf, shared.ErrOutOfRange)
+		}
+
+		o.maxLoad = lf
+
+		return nil
+	}
+}
+
+// WithCapacity grows the hashmap, so that it contains at least n elements.
+func WithCapacity(n uintptr) Option {
+	return func(o *options) error {
+		o.capacity = n
+		return nil
+	}
+}
+
+// WithEmptyKey sets the key, that marks empty buckets of the `Flat` hashmap.
+// The key type must match the hashmap, otherwise `NewHashMap` returns ErrTypeMismatch.
+func WithEmptyKey[K comparable](empty K) Option {
+	return func(o *options) error {
+		o.empty = empty
+		o.restrict("empty key", Flat)
+
+		return nil
+	}
+}
+
+// WithLayout selects the memory layout of the open addressing hashmaps.
+// Returns ErrOutOfRange if `l` is unknown.
+func WithLayout(l shared.Layout) Option {
+	return func(o *options) error {
+		if l < shared.AutoLayout || l > shared.SoA {
+			return fmt.Errorf("layout %d: %w", l, shared.ErrOutOfRange)
+		}
+
+		o.layout = l
+		o.restrict("layout", Hopscotch, Robin, Flat)
+
+		return nil
+	}
+}
+
+// WithLazyClear makes `Clear` of the open addressing hashmaps O(1) by generation stamps.
+func WithLazyClear(on bool) Option {
+	return func(o *options) error {
+		o.lazyClear = on
+		o.restrict("lazy clear", Hopscotch, Robin, Flat)
+
+		return nil
+	}
+}
+
+// WithSortKeys enables sorted keys for `MarshalJSON`.
+func WithSortKeys(on bool) Option {
+	return func(o *options) error {
+		o.sortKeys = on
+		return nil
+	}
+}
+
+// WithNeighborhoodSize sets the size of the neighborhood of the `Hopscotch` hashmap.
+// Returns ErrOutOfRange if `n` is zero or exceeds the width of the neighborhood.
+func WithNeighborhoodSize(n uintptr) Option {
+	return func(o *options) error {
+		if n == 0 {
+			return fmt.Errorf("neighborhood size %d: %w", n, shared.ErrOutOfRange)
+		}
+
+		o.neighborhoodSize = n
+		o.restrict("neighborhood size", Hopscotch)
+
+		return nil
+	}
+}
+
+// WithOverflowSize sets the upper bound of the overflow list of the `Hopscotch` hashmap.
+func WithOverflowSize(n uintptr) Option {
+	return func(o *options) error {
+		o.overflowSize = &n
+		o.restrict("overflow size", Hopscotch)
+
+		return nil
+	}
+}
+
+// WithSearchStrategy sets the algorithm, that is used by the `Robin` hashmap for lookups.
+// Returns ErrOutOfRange if `s` is unknown.
+func WithSearchStrategy(s robin.Strategy) Option {
+	return func(o *options) error {
+		if s < robin.LinearSearch || s > robin.OrganPipeSearch {
+			return fmt.Errorf("search strategy %d: %w", s, shared.ErrOutOfRange)
+		}
+
+		o.strategy = &s
+		o.restrict("search strategy", Robin)
+
+		return nil
+	}
+}
+
+// configOptions translates the config into options. Fields that are not
+// supported by the type of the hashmap are ignored.
+func configOptions[K comparable, V any](cfg Config[K, V]) []Option {
+	opts := []Option{WithSortKeys(cfg.SortKeys)}
+
+	if cfg.Algorithm != shared.Default || cfg.Seed != 0 {
+		opts = append(opts, WithAlgorithm(cfg.Algorithm, cfg.Seed))
+	}
+
+	if cfg.Hasher != nil {
+		opts = append(opts, WithHasher(cfg.Hasher))
+	}
+
+	if cfg.MaxLoad != 0 {
+		opts = append(opts, WithMaxLoad(cfg.MaxLoad))
+	}
+
+	if cfg.Size > 0 {
+		opts = append(opts, WithCapacity(cfg.Size))
+	}
+
+	switch cfg.Type {
+	case Flat:
+		opts = append(opts, WithEmptyKey(cfg.Empty), WithLayout(cfg.Layout), WithLazyClear(cfg.LazyClear))
+	case Hopscotch, Robin:
+		opts = append(opts, WithLayout(cfg.Layout), WithLazyClear(cfg.LazyClear))
+	}
+
+	return opts
+}
@@ -0,0 +1,36 @@
+package robin
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// SortKeys enables sorted keys for `MarshalJSON`, which makes the output
+// reproducible. Otherwise the keys are written in the order of `Each`.
+func (m *core[K, V, S]) SortKeys(on bool) {
+	if m.soa != nil {
+		m.soa.SortKeys(on)
+		return
+	}
+
+	m.sortKeys = on
+}
+
+// MarshalJSON implements the `json.Marshaler` interface. The keys must be
+// strings, integers or implement `encoding.TextMarshaler`, see `encoding/json`.
+func (m *core[K, V, S]) MarshalJSON() ([]byte, error) {
+	if m.soa != nil {
+		return m.soa.MarshalJSON()
+	}
+
+	return shared.MarshalJSON(m.Each, m.sortKeys)
+}
+
+// UnmarshalJSON implements the `json.Unmarshaler` interface. The members
+// are added by `Put` and the hashmap is sized by `Reserve` before.
+func (m *core[K, V, S]) UnmarshalJSON(data []byte) error {
+	if m.soa != nil {
+		return m.soa.UnmarshalJSON(data)
+	}
+
+	return shared.UnmarshalJSON(data, m.Reserve, m.Put)
+}
@@ -31,9 +31,19 @@ type bucket[K comparable, V any] struct {
 // also on the key and value size. For higher storage sizes for the
 // keys and values use a hashmap that uses another strategy
 // like the golang std hashmap. the Unordered hashmap.
+// Alternatively large values can be stored in a separate array, see `Layout`.
 type RobinHood[K comparable, V any] struct {
-	buckets []bucket[K, V]
-	hasher  shared.HashFn[K]
+	core[K, V, shared.AoSStorage]
+}
+
+// core implements the `RobinHood` hashmap for the storage S of the buckets.
+type core[K comparable, V any, S shared.Storage] struct {
+	// soa is set, if the hashmap uses the `shared.SoA` layout.
+	// All operations are forwarded to it.
+	soa *core[K, V, shared.SoAStorage]
+
+	table  table[K, V, S]
+	hasher shared.HashFn[K]
 	// length stores the current inserted elements
 	length uintptr
 	// capMinus1 is used for a bitwise AND on the hash value,
@@ -42,6 +52,14 @@ type RobinHood[K comparable, V any] struct {
 	nextResize uintptr
 
 	maxLoad float32
+	// sortKeys sorts the keys of `MarshalJSON`
+	sortKeys bool
+
+	// stats tracks the PSL distribution for the search strategies
+	stats    pslStats
+	strategy Strategy
+	// guard rejects structural changes during `EachMut`
+	guard shared.Guard
 }
 
 //go:inline
@@ -62,32 +80,61 @@ func New[K comparable, V any]() *RobinHood[K, V] {
 
 // NewWithHasher same as `NewRobinHood` but with a given hash function.
 func NewWithHasher[K comparable, V any](hasher shared.HashFn[K]) *RobinHood[K, V] {
-	m := &RobinHood[K, V]{
-		hasher:  hasher,
-		maxLoad: shared.DefaultMaxLoad,
+	m := &RobinHood[K, V]{}
+
+	if soa, _ := shared.UseSoA[V](shared.AutoLayout); soa {
+		m.soa = &core[K, V, shared.SoAStorage]{}
+		m.soa.init(hasher)
+	} else {
+		m.init(hasher)
 	}
-	m.Reserve(shared.DefaultSize)
 
 	return m
 }
 
+func (m *core[K, V, S]) init(hasher shared.HashFn[K]) {
+	m.hasher = hasher
+	m.maxLoad = shared.DefaultMaxLoad
+
+	m.Reserve(shared.DefaultSize)
+}
+
 // Get returns the value stored for this key, or false if there is no such value.
 //
 // Note:
 //   - There exists also other search strategies like organ-pipe search
 //     or smart search, where searching starts around the mean value
 //     (mean, mean − 1, mean + 1, mean − 2, mean + 2, ...)
-//   - Here it is used the simplest technic, which is more cache friendly and
-//     does not track other metic values.
-func (m *RobinHood[K, V]) Get(key K) (V, bool) {
+//   - By default it is used the simplest technic, which is more cache friendly.
+//     The others can be selected with `SearchStrategy`.
+func (m *core[K, V, S]) Get(key K) (V, bool) {
+	if m.soa != nil {
+		return m.soa.Get(key)
+	}
+
 	var (
 		idx = m.hasher(key) & m.capMinus1
 		v   V
 	)
 
-	for psl := int8(0); psl <= m.buckets[idx].psl; psl++ {
-		if m.buckets[idx].key == key {
-			return m.buckets[idx].value, true
+	switch m.strategy {
+	case SmartSearch:
+		if i, found := m.smartSearch(key, idx); found {
+			return *m.table.value(i), true
+		}
+
+		return v, false
+	case OrganPipeSearch:
+		if i, found := m.organPipeSearch(key, idx); found {
+			return *m.table.value(i), true
+		}
+
+		return v, false
+	}
+
+	for psl := int8(0); psl <= m.table.psl(idx); psl++ {
+		if m.table.key(idx) == key {
+			return *m.table.value(idx), true
 		}
 		// next index
 		idx = (idx + 1) & m.capMinus1
@@ -98,46 +145,71 @@ func (m *RobinHood[K, V]) Get(key K) (V, bool) {
 
 // Reserve sets the number of buckets to the most appropriate to contain at least n elements.
 // If n is lower than that, the function may have no effect.
-func (m *RobinHood[K, V]) Reserve(n uintptr) {
-	var (
-		needed = uintptr(float32(n) / m.maxLoad)
-		newCap = uintptr(shared.NextPowerOf2(uint64(needed)))
-	)
+func (m *core[K, V, S]) Reserve(n uintptr) {
+	if m.soa != nil {
+		m.soa.Reserve(n)
+		return
+	}
+
+	m.guard.Check()
 
-	if uintptr(cap(m.buckets)) < newCap {
+	newCap := shared.Capacity(n, m.maxLoad)
+
+	if m.table.len() < newCap {
 		m.resize(newCap)
 	}
 }
 
-func (m *RobinHood[K, V]) resize(n uintptr) {
-	newm := RobinHood[K, V]{
+// resize rebuilds the hashmap with `n` buckets.
+func (m *core[K, V, S]) resize(n uintptr) {
+	newm := rebuild[S](m, n)
+
+	m.nextResize = newm.nextResize
+	m.capMinus1 = newm.capMinus1
+	m.table = newm.table
+	m.stats = newm.stats
+}
+
+// rebuild returns a copy of the hashmap `m` with `n` buckets in the storage T.
+func rebuild[T, S shared.Storage, K comparable, V any](m *core[K, V, S], n uintptr) core[K, V, T] {
+	newm := core[K, V, T]{
 		capMinus1:  n - 1,
 		length:     m.length,
-		buckets:    newBucketArray[K, V](n),
+		table:      newTable[K, V, T](n, m.table.gens.Enabled()),
 		hasher:     m.hasher,
 		maxLoad:    m.maxLoad,
-		nextResize: uintptr(float32(n) * m.maxLoad),
+		nextResize: shared.Threshold(n, m.maxLoad),
+		stats:      pslStats{ordered: m.stats.ordered},
+		strategy:   m.strategy,
+		sortKeys:   m.sortKeys,
 	}
 
-	for i := range m.buckets {
-		if m.buckets[i].psl != emptyBucket {
-			idx := newm.hasher(m.buckets[i].key) & newm.capMinus1
-			m.buckets[i].psl = 0
-			newm.emplace(&m.buckets[i], idx)
+	newm.stats.reset()
+
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if m.table.psl(i) != emptyBucket {
+			current := m.table.load(i)
+			idx := newm.hasher(current.key) & newm.capMinus1
+			current.psl = 0
+			newm.emplace(&current, idx)
 		}
 	}
 
-	m.nextResize = newm.nextResize
-	m.capMinus1 = newm.capMinus1
-	m.buckets = newm.buckets
+	return newm
 }
 
 // Put adds the given key-value pair to the hashmap. If the key already exists its
 // value will be overwritten with the new value.
 // Returns true, if the element is a new item in the hashmap.
-func (m *RobinHood[K, V]) Put(key K, val V) bool {
+func (m *core[K, V, S]) Put(key K, val V) bool {
+	if m.soa != nil {
+		return m.soa.Put(key, val)
+	}
+
+	m.guard.Check()
+
 	if m.length >= m.nextResize {
-		m.resize(uintptr(cap(m.buckets)) * 2)
+		m.resize(m.table.len() * 2)
 	}
 
 	var (
@@ -146,9 +218,9 @@ func (m *RobinHood[K, V]) Put(key K, val V) bool {
 	)
 
 	// search for the key
-	for ; psl <= m.buckets[idx].psl; psl++ {
-		if m.buckets[idx].key == key {
-			m.buckets[idx].value = val
+	for ; psl <= m.table.psl(idx); psl++ {
+		if m.table.key(idx) == key {
+			m.table.setValue(idx, val)
 			return false // update already existing value
 		}
 		// next index
@@ -172,17 +244,22 @@ func (m *RobinHood[K, V]) Put(key K, val V) bool {
 // where the expected length of the longest PSL is O(log(n)).
 //
 //go:inline
-func (m *RobinHood[K, V]) emplace(current *bucket[K, V], idx uintptr) {
+func (m *core[K, V, S]) emplace(current *bucket[K, V], idx uintptr) {
 	for ; ; current.psl++ {
-		if m.buckets[idx].psl == emptyBucket {
+		psl := m.table.psl(idx)
+		if psl == emptyBucket {
 			// emplace the element, a valid bucket was found
-			m.buckets[idx] = *current
+			m.table.store(idx, current)
+			m.stats.inc(current.psl)
+
 			return
 		}
 
-		if current.psl > m.buckets[idx].psl {
+		if current.psl > psl {
 			// swap values, apply the Robin Hood creed
-			*current, m.buckets[idx] = m.buckets[idx], *current
+			m.stats.inc(current.psl)
+			m.stats.dec(psl)
+			m.table.swap(idx, current)
 		}
 
 		// next index
@@ -192,100 +269,222 @@ func (m *RobinHood[K, V]) emplace(current *bucket[K, V], idx uintptr) {
 
 // Remove removes the specified key-value pair from the hashmap.
 // Returns true, if the element was in the hashmap.
-func (m *RobinHood[K, V]) Remove(key K) bool {
+func (m *core[K, V, S]) Remove(key K) bool {
+	if m.soa != nil {
+		return m.soa.Remove(key)
+	}
+
+	m.guard.Check()
+
 	var (
-		idx     = m.hasher(key) & m.capMinus1
-		current *bucket[K, V]
+		idx   = m.hasher(key) & m.capMinus1
+		psl   = int8(0)
+		found = false
 	)
 
 	// search for the key
-	for psl := int8(0); psl <= m.buckets[idx].psl; psl++ {
-		if m.buckets[idx].key == key {
-			current = &m.buckets[idx]
+	for ; psl <= m.table.psl(idx); psl++ {
+		if m.table.key(idx) == key {
+			found = true
 			break
 		}
 		// next index
 		idx = (idx + 1) & m.capMinus1
 	}
 
-	if current == nil {
+	if !found {
 		return false
 	}
 
 	// remove the key
 	m.length--
 	// mark as empty, because we want to remove it
-	current.psl = emptyBucket
+	m.stats.dec(psl)
+	m.table.setPSL(idx, emptyBucket)
 
-	idx = (idx + 1) & m.capMinus1
-	next := &m.buckets[idx]
+	next := (idx + 1) & m.capMinus1
 	// now, back shift all buckets until we found a optimum or empty one
-	for next.psl > 0 {
-		next.psl--
-		*current, *next = *next, *current // swap values
-		current = next
-		idx = (idx + 1) & m.capMinus1
-		next = &m.buckets[idx]
+	for psl = m.table.psl(next); psl > 0; psl = m.table.psl(next) {
+		m.stats.dec(psl)
+		m.stats.inc(psl - 1)
+		m.table.shiftBack(next, idx)
+		idx = next
+		next = (next + 1) & m.capMinus1
 	}
 
 	return true
 }
 
 // Clear removes all key-value pairs from the hashmap.
-func (m *RobinHood[K, V]) Clear() {
-	for i := range m.buckets {
-		m.buckets[i].psl = emptyBucket
+func (m *core[K, V, S]) Clear() {
+	if m.soa != nil {
+		m.soa.Clear()
+		return
 	}
 
+	m.guard.Check()
+	m.table.clear()
+
 	m.length = 0
+	m.stats.reset()
 }
 
 // Load return the current load of the hashmap.
-func (m *RobinHood[K, V]) Load() float32 {
-	return float32(m.length) / float32(cap(m.buckets))
+func (m *core[K, V, S]) Load() float32 {
+	if m.soa != nil {
+		return m.soa.Load()
+	}
+
+	return float32(m.length) / float32(m.table.len())
 }
 
 // MaxLoad forces resizing if the ratio is reached.
 // Useful values are in range [0.5-0.9].
 // Returns ErrOutOfRange if `lf` is not in the open range (0.0,1.0).
-func (m *RobinHood[K, V]) MaxLoad(lf float32) error {
+func (m *core[K, V, S]) MaxLoad(lf float32) error {
+	if m.soa != nil {
+		return m.soa.MaxLoad(lf)
+	}
+
 	if lf <= 0.0 || lf >= 1.0 {
 		return fmt.Errorf("%f: %w", lf, shared.ErrOutOfRange)
 	}
 
 	m.maxLoad = lf
-	m.nextResize = uintptr(float32(cap(m.buckets)) * lf)
+	m.nextResize = shared.Threshold(m.table.len(), lf)
 
 	return nil
 }
 
+// SearchStrategy changes the algorithm that is used by `Get`.
+// The PSL distribution is tracked anyway, so the strategy can
+// be changed at any time. Returns ErrOutOfRange if `s` is unknown.
+func (m *core[K, V, S]) SearchStrategy(s Strategy) error {
+	if m.soa != nil {
+		return m.soa.SearchStrategy(s)
+	}
+
+	switch s {
+	case LinearSearch, SmartSearch:
+		m.stats.ordered = false
+	case OrganPipeSearch:
+		if !m.stats.ordered {
+			m.stats.ordered = true
+			m.stats.sortOrder()
+		}
+	default:
+		return fmt.Errorf("%d: %w", s, shared.ErrOutOfRange)
+	}
+
+	m.strategy = s
+
+	return nil
+}
+
+// Layout changes the memory layout of the buckets, see `shared.Layout`.
+// The hashmap is rebuilt, if the layout changes.
+// Returns ErrOutOfRange if `l` is unknown.
+func (m *RobinHood[K, V]) Layout(l shared.Layout) error {
+	soa, err := shared.UseSoA[V](l)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case soa && m.soa == nil:
+		m.guard.Check()
+
+		newm := rebuild[shared.SoAStorage](&m.core, m.table.len())
+		m.core = core[K, V, shared.AoSStorage]{soa: &newm}
+	case !soa && m.soa != nil:
+		m.soa.guard.Check()
+		m.core = rebuild[shared.AoSStorage](m.soa, m.soa.table.len())
+	}
+
+	return nil
+}
+
+// LazyClear enables generation stamps, so `Clear` starts a new generation
+// in O(1) instead of walking all buckets. Buckets of an older generation
+// are treated as empty and the stamps are wiped every 255 calls of `Clear`.
+// The stamps cost one byte per bucket and the keys and values of the
+// cleared buckets are released only when they are overwritten.
+func (m *core[K, V, S]) LazyClear(on bool) {
+	if m.soa != nil {
+		m.soa.LazyClear(on)
+		return
+	}
+
+	m.table.lazyClear(on)
+}
+
 // Size returns the number of items in the hashmap.
-func (m *RobinHood[K, V]) Size() int {
+func (m *core[K, V, S]) Size() int {
+	if m.soa != nil {
+		return m.soa.Size()
+	}
+
 	return int(m.length)
 }
 
 // Copy returns a copy of this hashmap.
 func (m *RobinHood[K, V]) Copy() *RobinHood[K, V] {
-	newM := &RobinHood[K, V]{
-		buckets:    make([]bucket[K, V], cap(m.buckets)),
+	if m.soa != nil {
+		newm := m.soa.copy()
+		return &RobinHood[K, V]{core[K, V, shared.AoSStorage]{soa: &newm}}
+	}
+
+	return &RobinHood[K, V]{m.copy()}
+}
+
+func (m *core[K, V, S]) copy() core[K, V, S] {
+	return core[K, V, S]{
+		table:      m.table.copy(),
 		capMinus1:  m.capMinus1,
 		length:     m.length,
 		hasher:     m.hasher,
 		maxLoad:    m.maxLoad,
 		nextResize: m.nextResize,
+		stats:      m.stats,
+		strategy:   m.strategy,
+		sortKeys:   m.sortKeys,
 	}
-
-	copy(newM.buckets, m.buckets)
-
-	return newM
 }
 
 // Each calls 'fn' on every key-value pair in the hash map in no particular order.
 // If 'fn' returns true, the iteration stops.
-func (m *RobinHood[K, V]) Each(fn func(key K, val V) bool) {
-	for i := range m.buckets {
-		if m.buckets[i].psl != emptyBucket {
-			if stop := fn(m.buckets[i].key, m.buckets[i].value); stop {
+func (m *core[K, V, S]) Each(fn func(key K, val V) bool) {
+	if m.soa != nil {
+		m.soa.Each(fn)
+		return
+	}
+
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if m.table.psl(i) != emptyBucket {
+			if stop := fn(m.table.key(i), *m.table.value(i)); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+}
+
+// EachMut calls 'fn' on every key-value pair in the hashmap in no particular order.
+// The value can be modified through the pointer, which is valid until 'fn' returns.
+// Structural changes like `Put`, `Remove` or `Clear` within 'fn' panic with
+// `shared.ErrIterating`. If 'fn' returns true, the iteration stops.
+func (m *core[K, V, S]) EachMut(fn func(key K, val *V) bool) {
+	if m.soa != nil {
+		m.soa.EachMut(fn)
+		return
+	}
+
+	m.guard.Enter()
+	defer m.guard.Leave()
+
+	for i := uintptr(0); i < m.table.len(); i++ {
+		if m.table.psl(i) != emptyBucket {
+			if stop := fn(m.table.key(i), m.table.editValue(i)); stop {
 				// stop iteration
 				return
 			}
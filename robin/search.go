@@ -0,0 +1,201 @@
+package robin
+
+import (
+	"math"
+	"sort"
+)
+
+// Strategy selects the algorithm that is used by `Get` to find a key.
+type Strategy int
+
+const (
+	// LinearSearch scans the buckets starting at the home bucket until the
+	// PSL of a bucket is lower than the current distance. It is the most cache
+	// friendly strategy and the default.
+	LinearSearch Strategy = iota
+	// SmartSearch starts around the mean PSL of all stored elements
+	// (mean, mean − 1, mean + 1, mean − 2, mean + 2, ...).
+	SmartSearch
+	// OrganPipeSearch probes the distances in descending order of their
+	// frequency within the hashmap.
+	OrganPipeSearch
+)
+
+// maxPSL is the highest PSL that can be stored in a bucket.
+const maxPSL = math.MaxInt8
+
+// pslStats tracks the distribution of the PSL values of all stored elements.
+// It is updated incrementally on every movement of an element.
+type pslStats struct {
+	// count is a histogram, count[psl] is the number of elements with that PSL
+	count [maxPSL + 1]uintptr
+	sum   uintptr
+	max   int8
+	// order holds all distances sorted by their frequency. It is only
+	// maintained if `ordered` is set, see `OrganPipeSearch`.
+	order   [maxPSL + 1]int8
+	rank    [maxPSL + 1]uint8
+	ordered bool
+}
+
+// reset clears the histogram and keeps the ordering mode.
+func (s *pslStats) reset() {
+	*s = pslStats{ordered: s.ordered}
+	if s.ordered {
+		s.sortOrder()
+	}
+}
+
+// sortOrder rebuilds the frequency order from scratch.
+func (s *pslStats) sortOrder() {
+	for i := range s.order {
+		s.order[i] = int8(i)
+	}
+
+	sort.SliceStable(s.order[:], func(i, j int) bool {
+		return s.count[s.order[i]] > s.count[s.order[j]]
+	})
+
+	for r, psl := range s.order {
+		s.rank[psl] = uint8(r)
+	}
+}
+
+//go:inline
+func (s *pslStats) swap(r1, r2 uint8) {
+	s.order[r1], s.order[r2] = s.order[r2], s.order[r1]
+	s.rank[s.order[r1]] = r1
+	s.rank[s.order[r2]] = r2
+}
+
+// inc announces a new element with the given PSL.
+//
+//go:inline
+func (s *pslStats) inc(psl int8) {
+	s.count[psl]++
+	s.sum += uintptr(psl)
+
+	if psl > s.max {
+		s.max = psl
+	}
+
+	if s.ordered {
+		// move the distance towards the front
+		for r := s.rank[psl]; r > 0 && s.count[s.order[r-1]] < s.count[psl]; r-- {
+			s.swap(r-1, r)
+		}
+	}
+}
+
+// dec announces that a element with the given PSL is gone.
+//
+//go:inline
+func (s *pslStats) dec(psl int8) {
+	s.count[psl]--
+	s.sum -= uintptr(psl)
+
+	for s.max > 0 && s.count[s.max] == 0 {
+		s.max--
+	}
+
+	if s.ordered {
+		// move the distance towards the back
+		for r := s.rank[psl]; r < maxPSL && s.count[s.order[r+1]] > s.count[psl]; r++ {
+			s.swap(r, r+1)
+		}
+	}
+}
+
+// mean returns the rounded mean PSL for `n` elements.
+//
+//go:inline
+func (s *pslStats) mean(n uintptr) int {
+	if n == 0 {
+		return 0
+	}
+
+	return int((s.sum + n/2) / n)
+}
+
+// probe checks the bucket with distance `d` from the home bucket.
+// Because robin hood hashing keeps all elements of a cluster sorted
+// by their home bucket, a bucket with a lower PSL than `d` ends the
+// range of candidates and a bucket with a higher PSL starts it.
+// The range [lo, hi] of possible distances is narrowed accordingly.
+//
+//go:inline
+func (m *core[K, V, S]) probe(key K, home uintptr, d int, lo, hi *int) (uintptr, bool) {
+	idx := (home + uintptr(d)) & m.capMinus1
+	psl := int(m.table.psl(idx))
+
+	switch {
+	case psl == d:
+		if m.table.key(idx) == key {
+			return idx, true
+		}
+	case psl < d:
+		*hi = d - 1
+	default:
+		*lo = d + 1
+	}
+
+	return 0, false
+}
+
+// smartSearch probes the distances around the mean PSL.
+func (m *core[K, V, S]) smartSearch(key K, home uintptr) (uintptr, bool) {
+	var (
+		lo   = 0
+		hi   = int(m.stats.max)
+		mean = m.stats.mean(m.length)
+	)
+
+	for i := 0; lo <= hi; i++ {
+		if mean-i/2 < lo && mean+i/2 > hi {
+			// all remaining distances are out of range
+			break
+		}
+
+		// mean, mean - 1, mean + 1, mean - 2, mean + 2, ...
+		d := mean + i/2
+		if i%2 == 1 {
+			d = mean - (i+1)/2
+		}
+
+		if d < lo || d > hi || m.stats.count[d] == 0 {
+			continue
+		}
+
+		if idx, found := m.probe(key, home, d, &lo, &hi); found {
+			return idx, true
+		}
+	}
+
+	return 0, false
+}
+
+// organPipeSearch probes the distances in the order of their frequency.
+func (m *core[K, V, S]) organPipeSearch(key K, home uintptr) (uintptr, bool) {
+	var (
+		lo = 0
+		hi = int(m.stats.max)
+	)
+
+	for r := 0; lo <= hi && r <= maxPSL; r++ {
+		d := int(m.stats.order[r])
+		if m.stats.count[d] == 0 {
+			// all following distances are unused
+			break
+		}
+
+		if d < lo || d > hi {
+			continue
+		}
+
+		if idx, found := m.probe(key, home, d, &lo, &hi); found {
+			return idx, true
+		}
+	}
+
+	return 0, false
+}
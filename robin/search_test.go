@@ -0,0 +1,124 @@
+package robin_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EinfachAndy/hashmaps/robin"
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+var strategies = []robin.Strategy{
+	robin.LinearSearch,
+	robin.SmartSearch,
+	robin.OrganPipeSearch,
+}
+
+func TestSearchStrategies(t *testing.T) {
+	t.Parallel()
+
+	// a weak hasher to provoke long probe sequences
+	weak := func(k uint64) uintptr {
+		return uintptr(k % 13)
+	}
+
+	for _, s := range strategies {
+		var (
+			m    = robin.NewWithHasher[uint64, uint64](weak)
+			stdm = make(map[uint64]uint64)
+		)
+
+		assert.NoError(t, m.MaxLoad(0.95))
+		assert.NoError(t, m.SearchStrategy(s))
+
+		for i := 0; i < 20000; i++ {
+			key := uint64(rand.Intn(200))
+
+			switch rand.Intn(3) {
+			case 0:
+				m.Put(key, key+1)
+				stdm[key] = key + 1
+			case 1:
+				m.Remove(key)
+				delete(stdm, key)
+			case 2:
+				v1, ok1 := m.Get(key)
+				v2, ok2 := stdm[key]
+				assert.Equal(t, ok2, ok1, "strategy %d: lookup wrong state for %d", s, key)
+				assert.Equal(t, v2, v1, "strategy %d: lookup wrong value for %d", s, key)
+			}
+		}
+
+		for k, v := range stdm {
+			got, ok := m.Get(k)
+			assert.True(t, ok)
+			assert.Equal(t, v, got)
+		}
+	}
+}
+
+func TestSearchStrategyInvalid(t *testing.T) {
+	m := robin.New[int, int]()
+	assert.Error(t, m.SearchStrategy(robin.Strategy(-1)))
+	assert.NoError(t, m.SearchStrategy(robin.OrganPipeSearch))
+}
+
+func setupSearchBench(b *testing.B, s robin.Strategy, lf float32, n int) *robin.RobinHood[uint64, uint64] {
+	b.Helper()
+
+	m := robin.NewWithHasher[uint64, uint64](shared.GetHasher[uint64]())
+	if err := m.MaxLoad(lf); err != nil {
+		b.Fatal(err)
+	}
+
+	m.Reserve(uintptr(n))
+
+	if err := m.SearchStrategy(s); err != nil {
+		b.Fatal(err)
+	}
+
+	// only even keys are stored, odd keys are used for unsuccessful lookups
+	for i := 0; i < n; i++ {
+		m.Put(uint64(2*i), uint64(i))
+	}
+
+	return m
+}
+
+func BenchmarkGetMiss(b *testing.B) {
+	const n = 1 << 16
+
+	for _, lf := range []float32{0.5, 0.8, 0.95} {
+		for _, s := range strategies {
+			b.Run(fmt.Sprintf("load=%.2f/strategy=%d", lf, s), func(b *testing.B) {
+				m := setupSearchBench(b, s, lf, int(float32(1<<17)*lf)-1)
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					m.Get(uint64(2*(i%n) + 1))
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGetHit(b *testing.B) {
+	const n = 1 << 16
+
+	for _, lf := range []float32{0.5, 0.8, 0.95} {
+		for _, s := range strategies {
+			b.Run(fmt.Sprintf("load=%.2f/strategy=%d", lf, s), func(b *testing.B) {
+				m := setupSearchBench(b, s, lf, int(float32(1<<17)*lf)-1)
+				size := uint64(m.Size())
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					m.Get(2 * (uint64(i%n) % size))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package robin
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// Snapshot is a read-only view of a `RobinHood` hashmap at the time it was taken.
+// It shares the buckets with the hashmap, which copies a page of buckets only
+// before it modifies the page for the first time (copy-on-write). A resize or
+// `Clear` of the hashmap allocates new buckets, so the snapshot keeps the old ones.
+// A snapshot can be read concurrently to the modifications of the hashmap.
+type Snapshot[K comparable, V any] struct {
+	snapshot[K, V, shared.AoSStorage]
+}
+
+// snapshot implements the `Snapshot` for the storage S of the buckets.
+type snapshot[K comparable, V any, S shared.Storage] struct {
+	// soa is set, if the hashmap uses the `shared.SoA` layout.
+	// All operations are forwarded to it.
+	soa *snapshot[K, V, shared.SoAStorage]
+
+	table     table[K, V, S]
+	pages     *shared.Pages[table[K, V, S]]
+	hasher    shared.HashFn[K]
+	capMinus1 uintptr
+	length    uintptr
+}
+
+// Snapshot returns a read-only view of the hashmap in O(1). Call `Release`
+// if the snapshot is no longer needed, otherwise the hashmap keeps copying
+// the modified pages for it.
+func (m *RobinHood[K, V]) Snapshot() *Snapshot[K, V] {
+	if m.soa != nil {
+		s := m.soa.snapshot()
+		return &Snapshot[K, V]{snapshot[K, V, shared.AoSStorage]{soa: &s}}
+	}
+
+	return &Snapshot[K, V]{m.snapshot()}
+}
+
+func (m *core[K, V, S]) snapshot() snapshot[K, V, S] {
+	m.guard.Check()
+
+	if m.table.cow == nil {
+		m.table.cow = shared.NewCOW[table[K, V, S]](m.table.len())
+	}
+
+	s := snapshot[K, V, S]{
+		table:     m.table,
+		pages:     m.table.cow.Snapshot(),
+		hasher:    m.hasher,
+		capMinus1: m.capMinus1,
+		length:    m.length,
+	}
+	s.table.cow = nil
+
+	return s
+}
+
+// at returns the table, that holds the i-th bucket, and the index within this table.
+//
+//go:inline
+func (s *snapshot[K, V, S]) at(i uintptr) (*table[K, V, S], uintptr) {
+	if p := s.pages.Page(i); p != nil {
+		return p, i & shared.PageMask
+	}
+
+	return &s.table, i
+}
+
+// Get returns the value stored for this key, or false if there is no such value.
+func (s *snapshot[K, V, S]) Get(key K) (V, bool) {
+	if s.soa != nil {
+		return s.soa.Get(key)
+	}
+
+	var (
+		idx = s.hasher(key) & s.capMinus1
+		v   V
+	)
+
+	s.pages.RLock()
+	defer s.pages.RUnlock()
+
+	for psl := int8(0); ; psl++ {
+		t, i := s.at(idx)
+		if psl > t.psl(i) {
+			return v, false
+		}
+
+		if t.key(i) == key {
+			return *t.value(i), true
+		}
+		// next index
+		idx = (idx + 1) & s.capMinus1
+	}
+}
+
+// Size returns the number of items in the snapshot.
+func (s *snapshot[K, V, S]) Size() int {
+	if s.soa != nil {
+		return s.soa.Size()
+	}
+
+	return int(s.length)
+}
+
+// Each calls 'fn' on every key-value pair in the snapshot in no particular order.
+// If 'fn' returns true, the iteration stops.
+// The hashmap may be modified within 'fn'.
+func (s *snapshot[K, V, S]) Each(fn func(key K, val V) bool) {
+	if s.soa != nil {
+		s.soa.Each(fn)
+		return
+	}
+
+	for idx := uintptr(0); idx <= s.capMinus1; idx++ {
+		if b, ok := s.load(idx); ok {
+			if stop := fn(b.key, b.value); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+}
+
+// load returns a copy of the i-th bucket, or false if it is empty.
+func (s *snapshot[K, V, S]) load(idx uintptr) (bucket[K, V], bool) {
+	s.pages.RLock()
+	defer s.pages.RUnlock()
+
+	t, i := s.at(idx)
+	if t.psl(i) == emptyBucket {
+		return bucket[K, V]{}, false
+	}
+
+	return t.load(i), true
+}
+
+// Release unregisters the snapshot from the hashmap.
+// The snapshot must not be used afterwards.
+func (s *snapshot[K, V, S]) Release() {
+	if s.soa != nil {
+		s.soa.Release()
+		return
+	}
+
+	s.pages.Release()
+}
@@ -0,0 +1,253 @@
+package robin
+
+import (
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+// table stores the buckets of the hashmap. Depending on the storage S the
+// buckets are interleaved in one array (AoS) or the PSLs, keys and values
+// live in separate arrays (SoA). All accesses to the buckets are done
+// through the table, so the hashmap itself is layout agnostic.
+type table[K comparable, V any, S shared.Storage] struct {
+	// buckets is used by the AoS layout
+	buckets []bucket[K, V]
+	// psls, keys and values are used by the SoA layout
+	psls   []int8
+	keys   []K
+	values []V
+	// cow is set, if snapshots share the buckets, see `preserve`
+	cow *shared.COW[table[K, V, S]]
+	// gens is enabled by `LazyClear`, stale buckets are empty
+	gens shared.Generations
+}
+
+//go:inline
+func newTable[K comparable, V any, S shared.Storage](capacity uintptr, lazy bool) table[K, V, S] {
+	var t table[K, V, S]
+
+	if lazy {
+		t.gens = shared.NewGenerations(capacity)
+	}
+
+	if !shared.IsSoA[S]() {
+		t.buckets = newBucketArray[K, V](capacity)
+		return t
+	}
+
+	t.psls = make([]int8, capacity)
+	t.keys = make([]K, capacity)
+	t.values = make([]V, capacity)
+
+	for i := range t.psls {
+		t.psls[i] = emptyBucket
+	}
+
+	return t
+}
+
+// len returns the number of buckets.
+//
+//go:inline
+func (t *table[K, V, S]) len() uintptr {
+	if shared.IsSoA[S]() {
+		return uintptr(len(t.psls))
+	}
+
+	return uintptr(len(t.buckets))
+}
+
+//go:inline
+func (t *table[K, V, S]) psl(i uintptr) int8 {
+	if t.gens.Stale(i) {
+		return emptyBucket
+	}
+
+	if shared.IsSoA[S]() {
+		return t.psls[i]
+	}
+
+	return t.buckets[i].psl
+}
+
+// preserve must be called before the i-th bucket is modified.
+// It copies the page of the bucket for the snapshots, that share it.
+//
+//go:inline
+func (t *table[K, V, S]) preserve(i uintptr) {
+	if t.cow != nil && !t.cow.Preserved(i) {
+		t.cow.Preserve(i, t.len(), t.copyRange)
+	}
+}
+
+//go:inline
+func (t *table[K, V, S]) setPSL(i uintptr, psl int8) {
+	t.preserve(i)
+	t.gens.Stamp(i)
+
+	if shared.IsSoA[S]() {
+		t.psls[i] = psl
+	} else {
+		t.buckets[i].psl = psl
+	}
+}
+
+//go:inline
+func (t *table[K, V, S]) key(i uintptr) K {
+	if shared.IsSoA[S]() {
+		return t.keys[i]
+	}
+
+	return t.buckets[i].key
+}
+
+// value returns a pointer to the value of the i-th bucket.
+//
+//go:inline
+func (t *table[K, V, S]) value(i uintptr) *V {
+	if shared.IsSoA[S]() {
+		return &t.values[i]
+	}
+
+	return &t.buckets[i].value
+}
+
+//go:inline
+func (t *table[K, V, S]) setValue(i uintptr, val V) {
+	t.preserve(i)
+	*t.value(i) = val
+}
+
+// editValue returns a pointer to the value of the i-th bucket,
+// which can be modified without affecting a snapshot.
+//
+//go:inline
+func (t *table[K, V, S]) editValue(i uintptr) *V {
+	t.preserve(i)
+	return t.value(i)
+}
+
+// load returns a copy of the i-th bucket.
+//
+//go:inline
+func (t *table[K, V, S]) load(i uintptr) bucket[K, V] {
+	if shared.IsSoA[S]() {
+		return bucket[K, V]{key: t.keys[i], psl: t.psls[i], value: t.values[i]}
+	}
+
+	return t.buckets[i]
+}
+
+// store overwrites the i-th bucket with b.
+//
+//go:inline
+func (t *table[K, V, S]) store(i uintptr, b *bucket[K, V]) {
+	t.preserve(i)
+	t.gens.Stamp(i)
+
+	if shared.IsSoA[S]() {
+		t.psls[i] = b.psl
+		t.keys[i] = b.key
+		t.values[i] = b.value
+	} else {
+		t.buckets[i] = *b
+	}
+}
+
+// swap exchanges the i-th bucket with b.
+//
+//go:inline
+func (t *table[K, V, S]) swap(i uintptr, b *bucket[K, V]) {
+	t.preserve(i)
+
+	if shared.IsSoA[S]() {
+		t.psls[i], b.psl = b.psl, t.psls[i]
+		t.keys[i], b.key = b.key, t.keys[i]
+		t.values[i], b.value = b.value, t.values[i]
+	} else {
+		t.buckets[i], *b = *b, t.buckets[i]
+	}
+}
+
+// shiftBack moves the bucket `from` to the free bucket `to`, decrements
+// its PSL and marks `from` as empty.
+//
+//go:inline
+func (t *table[K, V, S]) shiftBack(from, to uintptr) {
+	t.preserve(from)
+	t.preserve(to)
+	t.gens.Stamp(to)
+
+	if shared.IsSoA[S]() {
+		t.psls[to] = t.psls[from] - 1
+		t.keys[to] = t.keys[from]
+		t.values[to] = t.values[from]
+		t.psls[from] = emptyBucket
+	} else {
+		t.buckets[to] = t.buckets[from]
+		t.buckets[to].psl--
+		t.buckets[from].psl = emptyBucket
+	}
+}
+
+// clear marks all buckets as empty. If snapshots share
+// the buckets, a new table is allocated instead.
+func (t *table[K, V, S]) clear() {
+	if t.cow != nil {
+		*t = newTable[K, V, S](t.len(), t.gens.Enabled())
+		return
+	}
+
+	if t.gens.Enabled() {
+		t.gens.Next()
+		return
+	}
+
+	if shared.IsSoA[S]() {
+		for i := range t.psls {
+			t.psls[i] = emptyBucket
+		}
+	} else {
+		for i := range t.buckets {
+			t.buckets[i].psl = emptyBucket
+		}
+	}
+}
+
+// lazyClear enables or disables the generation stamps. The stale
+// buckets are marked as empty, before the stamps are dropped.
+func (t *table[K, V, S]) lazyClear(on bool) {
+	switch {
+	case on && !t.gens.Enabled():
+		t.gens = shared.NewGenerations(t.len())
+	case !on && t.gens.Enabled():
+		for i := uintptr(0); i < t.len(); i++ {
+			if t.gens.Stale(i) {
+				t.setPSL(i, emptyBucket)
+			}
+		}
+
+		t.gens = shared.Generations{}
+	}
+}
+
+// copy returns a deep copy of the table.
+func (t *table[K, V, S]) copy() table[K, V, S] {
+	return t.copyRange(0, t.len())
+}
+
+// copyRange returns a deep copy of the buckets in range [lo,hi).
+func (t *table[K, V, S]) copyRange(lo, hi uintptr) table[K, V, S] {
+	if shared.IsSoA[S]() {
+		return table[K, V, S]{
+			psls:   append([]int8(nil), t.psls[lo:hi]...),
+			keys:   append([]K(nil), t.keys[lo:hi]...),
+			values: append([]V(nil), t.values[lo:hi]...),
+			gens:   t.gens.Copy(lo, hi),
+		}
+	}
+
+	return table[K, V, S]{
+		buckets: append([]bucket[K, V](nil), t.buckets[lo:hi]...),
+		gens:    t.gens.Copy(lo, hi),
+	}
+}
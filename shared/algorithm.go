@@ -0,0 +1,224 @@
+package shared
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"hash/maphash"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// Algorithm selects a family of hash functions, which is a trade-off
+// between speed and the quality of the distribution.
+type Algorithm int
+
+const (
+	// Default are the hash functions of `GetHasher`. They are the fastest
+	// for integer keys, but do not support a seed.
+	Default Algorithm = iota
+	// WyHash is wyhash final version 4, which is fast for all key types.
+	WyHash
+	// XXH3 is the 64 bit variant of XXH3.
+	XXH3
+	// CRC32C uses the hardware CRC32-C instructions, where available, but only
+	// for strings and `Algorithm.Sum64`. Integer and float keys are hashed by a
+	// table in software, because the bytes passed to `crc32.Update` escape to
+	// the heap and the allocation costs more than the table lookups.
+	// The checksum has only 32 bits, which are spread by a finalizer.
+	CRC32C
+	// MapHash is the hash function of the golang runtime, which uses AES
+	// instructions, where available. The hash values differ between processes,
+	// the seed is mixed into the hash values.
+	MapHash
+)
+
+var algorithmNames = [...]string{"default", "wyhash", "xxh3", "crc32c", "maphash"}
+
+// Algorithms returns all hash algorithms.
+func Algorithms() []Algorithm {
+	return []Algorithm{Default, WyHash, XXH3, CRC32C, MapHash}
+}
+
+// String returns the name of the algorithm.
+func (a Algorithm) String() string {
+	if a < Default || a > MapHash {
+		return fmt.Sprintf("Algorithm(%d)", int(a))
+	}
+
+	return algorithmNames[a]
+}
+
+// ParseAlgorithm returns the algorithm of the case insensitive name.
+// Returns ErrUnsupported if the name is unknown.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, a := range Algorithms() {
+		if strings.EqualFold(a.String(), name) {
+			return a, nil
+		}
+	}
+
+	return 0, fmt.Errorf("algorithm %q: %w", name, ErrUnsupported)
+}
+
+var (
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+	mapSeed    = maphash.MakeSeed()
+)
+
+// Sum64 returns the hash of the bytes with the given seed.
+// The seed is ignored by `Default`.
+func (a Algorithm) Sum64(data []byte, seed uint64) uint64 {
+	switch a {
+	case WyHash:
+		return wyHash(data, wySeed(seed))
+	case XXH3:
+		return xxh3(data, seed)
+	case CRC32C:
+		return crcSum64(data, seed)
+	case MapHash:
+		return mix64(maphash.Bytes(mapSeed, data) ^ seed)
+	default:
+		return fnv1a(unsafe.String(unsafe.SliceData(data), len(data)))
+	}
+}
+
+// GetSeededHasher returns a hasher of the algorithm for the golang default types.
+// Except for `Default`, integer and float keys are hashed as 8 bytes in little
+// endian order and strings by their bytes, so the hash values match `Algorithm.Sum64`.
+// Returns ErrOutOfRange if the algorithm is unknown and ErrUnsupported
+// if the key type is not supported.
+func GetSeededHasher[Key any](a Algorithm, seed uint64) (HashFn[Key], error) {
+	var (
+		key  Key
+		kind = reflect.ValueOf(&key).Elem().Type().Kind()
+	)
+
+	if a < Default || a > MapHash {
+		return nil, fmt.Errorf("algorithm %d: %w", a, ErrOutOfRange)
+	}
+
+	switch kind {
+	case reflect.Int, reflect.Uint, reflect.Uintptr,
+		reflect.Int8, reflect.Uint8, reflect.Int16, reflect.Uint16,
+		reflect.Int32, reflect.Uint32, reflect.Int64, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if a == Default {
+			return GetHasher[Key](), nil
+		}
+
+		return intHasher[Key](a.sum64Word(seed)), nil
+	case reflect.String:
+		if a == Default {
+			return GetHasher[Key](), nil
+		}
+
+		hash := a.sumString(seed)
+
+		return func(key Key) uintptr {
+			return Fold(hash(*(*string)(unsafe.Pointer(&key))))
+		}, nil
+	default:
+		return nil, fmt.Errorf("key type %T of kind %v: %w", key, kind, ErrUnsupported)
+	}
+}
+
+// sum64Word returns the hash function of 8 bytes in little endian order.
+func (a Algorithm) sum64Word(seed uint64) func(key uint64) uint64 {
+	switch a {
+	case WyHash:
+		seed = wySeed(seed)
+
+		return func(key uint64) uint64 {
+			return wyHash64(key, seed)
+		}
+	case XXH3:
+		return func(key uint64) uint64 {
+			return xxh3Len4To8(key&0xFFFFFFFF, key>>32, 8, seed)
+		}
+	case CRC32C:
+		// the table is used, because crc32.Update lets the bytes escape,
+		// which doubles the time per key by the allocation
+		return func(key uint64) uint64 {
+			crc := ^uint32(seed)
+
+			for i := 0; i < 8; i++ {
+				crc = castagnoli[byte(crc)^byte(key>>(8*i))] ^ crc>>8
+			}
+
+			return mix64(uint64(^crc) ^ seed&^0xFFFFFFFF)
+		}
+	default:
+		return func(key uint64) uint64 {
+			var b [8]byte
+
+			binary.LittleEndian.PutUint64(b[:], key)
+
+			return mix64(maphash.Bytes(mapSeed, b[:]) ^ seed)
+		}
+	}
+}
+
+// sumString returns the hash function of the bytes of a string.
+func (a Algorithm) sumString(seed uint64) func(s string) uint64 {
+	switch a {
+	case WyHash:
+		seed = wySeed(seed)
+
+		return func(s string) uint64 {
+			return wyHash(unsafe.Slice(unsafe.StringData(s), len(s)), seed)
+		}
+	case MapHash:
+		return func(s string) uint64 {
+			return mix64(maphash.String(mapSeed, s) ^ seed)
+		}
+	default:
+		return func(s string) uint64 {
+			return a.Sum64(unsafe.Slice(unsafe.StringData(s), len(s)), seed)
+		}
+	}
+}
+
+// intHasher reads the integer or float key by its size and widens it to 64 bits.
+func intHasher[Key any](hash func(key uint64) uint64) HashFn[Key] {
+	var key Key
+
+	switch unsafe.Sizeof(key) {
+	case 1:
+		return func(key Key) uintptr {
+			return Fold(hash(uint64(*(*uint8)(unsafe.Pointer(&key)))))
+		}
+	case 2:
+		return func(key Key) uintptr {
+			return Fold(hash(uint64(*(*uint16)(unsafe.Pointer(&key)))))
+		}
+	case 4:
+		return func(key Key) uintptr {
+			return Fold(hash(uint64(*(*uint32)(unsafe.Pointer(&key)))))
+		}
+	default:
+		return func(key Key) uintptr {
+			return Fold(hash(*(*uint64)(unsafe.Pointer(&key))))
+		}
+	}
+}
+
+// crcSum64 computes the checksum with the lower half of the seed
+// and spreads it with the upper half to 64 bits.
+func crcSum64(data []byte, seed uint64) uint64 {
+	crc := crc32.Update(uint32(seed), castagnoli, data)
+
+	return mix64(uint64(crc) ^ seed&^0xFFFFFFFF)
+}
+
+// mix64 implements MurmurHash3's 64-bit Finalizer.
+func mix64(key uint64) uint64 {
+	key ^= key >> 33
+	key *= 0xff51afd7ed558ccd
+	key ^= key >> 33
+	key *= 0xc4ceb9fe1a85ec53
+	key ^= key >> 33
+
+	return key
+}
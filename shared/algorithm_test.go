@@ -0,0 +1,170 @@
+package shared_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+func TestAlgorithm(t *testing.T) {
+	// reference value of XXH3 for the empty input
+	assert.Equal(t, uint64(0x2D06800538D394C2), shared.XXH3.Sum64(nil, 0))
+
+	data := make([]byte, 2100)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	for _, a := range shared.Algorithms() {
+		parsed, err := shared.ParseAlgorithm(a.String())
+		assert.NoError(t, err)
+		assert.Equal(t, a, parsed)
+
+		// covers all code paths of the lengths
+		seen := make(map[uint64]int)
+
+		for n := 0; n <= len(data); n++ {
+			h := a.Sum64(data[:n], 42)
+			assert.Equal(t, h, a.Sum64(data[:n], 42))
+
+			prev, ok := seen[h]
+			assert.False(t, ok, "%v: length %d collides with %d", a, n, prev)
+			seen[h] = n
+		}
+
+		if a == shared.Default {
+			continue
+		}
+
+		assert.NotEqual(t, a.Sum64(data, 1), a.Sum64(data, 2), a)
+		assert.NotEqual(t, a.Sum64(data[:300], 1), a.Sum64(data[:300], 2), a)
+
+		var (
+			intHasher, _  = shared.GetSeededHasher[uint64](a, 7)
+			byteHasher, _ = shared.GetSeededHasher[int8](a, 7)
+			strHasher, _  = shared.GetSeededHasher[string](a, 7)
+			buf           [8]byte
+		)
+
+		for i := uint64(0); i < 1000; i++ {
+			binary.LittleEndian.PutUint64(buf[:], i*0x9E3779B97F4A7C15)
+			assert.Equal(t, shared.Fold(a.Sum64(buf[:], 7)), intHasher(i*0x9E3779B97F4A7C15), a)
+
+			binary.LittleEndian.PutUint64(buf[:], uint64(uint8(i)))
+			assert.Equal(t, shared.Fold(a.Sum64(buf[:], 7)), byteHasher(int8(i)), a)
+
+			s := string(data[:i])
+			assert.Equal(t, shared.Fold(a.Sum64(data[:i], 7)), strHasher(s), a)
+		}
+	}
+
+	_, err := shared.ParseAlgorithm("md5")
+	assert.ErrorIs(t, err, shared.ErrUnsupported)
+
+	_, err = shared.GetSeededHasher[int](shared.MapHash+1, 0)
+	assert.ErrorIs(t, err, shared.ErrOutOfRange)
+
+	_, err = shared.GetSeededHasher[struct{ a int }](shared.WyHash, 0)
+	assert.ErrorIs(t, err, shared.ErrUnsupported)
+
+	assert.Equal(t, "Algorithm(-1)", shared.Algorithm(-1).String())
+}
+
+func TestAlgorithmVectors(t *testing.T) {
+	// the test vectors of the reference implementation of wyhash final version 4,
+	// where the seed is the index of the input
+	for i, want := range []struct {
+		in  string
+		sum uint64
+	}{
+		{"", 0x93228a4de0eec5a2},
+		{"a", 0xc5bac3db178713c4},
+		{"abc", 0xa97f2f7b1d9b3314},
+		{"message digest", 0x786d1f1df3801df4},
+		{"abcdefghijklmnopqrstuvwxyz", 0xdca5a8138ad37c87},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", 0xb9e734f117cfaf70},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", 0x6cc5eab49a92d617},
+	} {
+		assert.Equal(t, want.sum, shared.WyHash.Sum64([]byte(want.in), uint64(i)), want.in)
+	}
+
+	for _, want := range []struct {
+		in  string
+		sum uint64
+	}{
+		{"a", 0xe6c632b61e964e1f},
+		{"abc", 0x78af5f94892f3950},
+		{"hello world", 0xd447b1ea40e6988b},
+	} {
+		assert.Equal(t, want.sum, shared.XXH3.Sum64([]byte(want.in), 0), want.in)
+	}
+
+	// the test vectors of the reference implementation of XXH3 for all code
+	// paths of the lengths, where the i-th byte is (i+1)%251 and the seed of
+	// the seeded hash value is the hash value without a seed
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte((i + 1) % 251)
+	}
+
+	for _, want := range []struct {
+		n      int
+		sum    uint64
+		seeded uint64
+	}{
+		{0, 0x2d06800538d394c2, 0x412f1275e10017f3},
+		{1, 0xe12ef9d2eb86ceeb, 0x4262213496108755},
+		{3, 0xebce9b7632ae733b, 0xfb7293fb3dbdac25},
+		{4, 0x988b7b9033ac4622, 0x48f347023f9c957e},
+		{8, 0x16f217ea16232297, 0xdccb3547423b9f24},
+		{9, 0x17d143e7f447850a, 0xe1b6f82d24211d46},
+		{16, 0xeb5aeb9a32450f6a, 0xe9a6d2d94e8991c1},
+		{17, 0x6d458e1fff494078, 0x54019c5cc05b1fcc},
+		{128, 0xce22cae9106851df, 0x89f0b391c1134b63},
+		{129, 0x7d4fc663f5958d40, 0x5d32d64c42cb3d9e},
+		{240, 0xa5a910b2d7e065b0, 0x80e2216803241284},
+		{241, 0xb6515f490cdd4ce5, 0x5d5615c096fc7d65},
+		{1000, 0x30e4c5246f300d11, 0xc9e9935532f5e261},
+	} {
+		assert.Equal(t, want.sum, shared.XXH3.Sum64(data[:want.n], 0), want.n)
+		assert.Equal(t, want.seeded, shared.XXH3.Sum64(data[:want.n], want.sum), want.n)
+	}
+}
+
+func BenchmarkAlgorithm(b *testing.B) {
+	for _, a := range shared.Algorithms() {
+		hasher, _ := shared.GetSeededHasher[uint64](a, 1)
+
+		b.Run(a.String()+"/uint64", func(b *testing.B) {
+			var sum uintptr
+
+			for i := 0; i < b.N; i++ {
+				sum += hasher(uint64(i))
+			}
+
+			_ = sum
+		})
+
+		strHasher, _ := shared.GetSeededHasher[string](a, 1)
+
+		for _, n := range []int{8, 32, 256, 4096} {
+			key := string(make([]byte, n))
+
+			b.Run(fmt.Sprintf("%v/string%d", a, n), func(b *testing.B) {
+				var sum uintptr
+
+				b.SetBytes(int64(n))
+
+				for i := 0; i < b.N; i++ {
+					sum += strHasher(key)
+				}
+
+				_ = sum
+			})
+		}
+	}
+}
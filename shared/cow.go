@@ -0,0 +1,144 @@
+package shared
+
+import (
+	"sync"
+)
+
+const (
+	// PageShift defines the number of buckets per page (1 << PageShift),
+	// which is the unit of copy-on-write for the snapshots.
+	PageShift = 9
+	// PageMask is used to get the index of a bucket within its page.
+	PageMask = 1<<PageShift - 1
+)
+
+// COW tracks the snapshots of a bucket array. Before the hashmap modifies a
+// bucket, it calls `Preserve`, which copies the page of the bucket for all
+// snapshots, that still share this page. So a snapshot is taken in O(1) and
+// only the modified pages are copied. The snapshots may be read concurrently
+// to the modifications of the hashmap, but the hashmap itself, including
+// `Snapshot` and `Preserve`, must be used by one goroutine at a time.
+type COW[P any] struct {
+	mu sync.RWMutex
+	// epoch is incremented by each snapshot
+	epoch uint64
+	// saved stores per page the epoch of the last preservation
+	saved     []uint64
+	snapshots []*Pages[P]
+}
+
+// Pages holds the preserved pages of one snapshot. A nil page is still
+// shared with the hashmap.
+type Pages[P any] struct {
+	cow   *COW[P]
+	pages []*P
+	epoch uint64
+}
+
+// NewCOW creates the tracker for an array of `n` buckets.
+func NewCOW[P any](n uintptr) *COW[P] {
+	return &COW[P]{
+		saved: make([]uint64, (n+PageMask)>>PageShift),
+	}
+}
+
+// Snapshot registers a new snapshot, which shares all pages with the hashmap.
+func (c *COW[P]) Snapshot() *Pages[P] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	s := &Pages[P]{cow: c, epoch: c.epoch}
+	c.snapshots = append(c.snapshots, s)
+
+	return s
+}
+
+// Preserved returns true, if the page of bucket `i` can be modified
+// without affecting a snapshot.
+//
+//go:inline
+func (c *COW[P]) Preserved(i uintptr) bool {
+	return c.saved[i>>PageShift] >= c.epoch
+}
+
+// Preserve copies the page of bucket `i` for all snapshots, which share
+// the page with the hashmap. `copyPage` returns a copy of the buckets
+// in range [lo,hi).
+func (c *COW[P]) Preserve(i uintptr, n uintptr, copyPage func(lo, hi uintptr) P) {
+	var (
+		page = i >> PageShift
+		cp   *P
+	)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, s := range c.snapshots {
+		// the page is unchanged since the snapshot was taken
+		if s.epoch > c.saved[page] {
+			if cp == nil {
+				var (
+					lo = page << PageShift
+					hi = lo + PageMask + 1
+				)
+
+				if hi > n {
+					hi = n
+				}
+
+				p := copyPage(lo, hi)
+				cp = &p
+			}
+
+			if s.pages == nil {
+				s.pages = make([]*P, len(c.saved))
+			}
+
+			s.pages[page] = cp
+		}
+	}
+
+	c.saved[page] = c.epoch
+}
+
+// Page returns the preserved page of bucket `i` or nil, if the page
+// is still shared with the hashmap. The caller must hold the read lock.
+//
+//go:inline
+func (s *Pages[P]) Page(i uintptr) *P {
+	if s.pages == nil {
+		return nil
+	}
+
+	return s.pages[i>>PageShift]
+}
+
+// RLock locks the pages for reading, the hashmap waits with the
+// preservation of a page until the lock is released.
+func (s *Pages[P]) RLock() {
+	s.cow.mu.RLock()
+}
+
+// RUnlock undoes a single `RLock` call.
+func (s *Pages[P]) RUnlock() {
+	s.cow.mu.RUnlock()
+}
+
+// Release unregisters the snapshot, so the hashmap stops copying pages for it.
+func (s *Pages[P]) Release() {
+	c := s.cow
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, other := range c.snapshots {
+		if other == s {
+			c.snapshots[i] = c.snapshots[len(c.snapshots)-1]
+			c.snapshots[len(c.snapshots)-1] = nil
+			c.snapshots = c.snapshots[:len(c.snapshots)-1]
+
+			break
+		}
+	}
+}
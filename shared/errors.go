@@ -2,5 +2,15 @@ package shared
 
 import "errors"
 
-// ErrOutOfRange signals an out of range request.
-var ErrOutOfRange = errors.New("out of range")
+var (
+	// ErrOutOfRange signals an out of range request.
+	ErrOutOfRange = errors.New("out of range")
+	// ErrUnsupported signals an option, that is not supported by the type of the hashmap.
+	ErrUnsupported = errors.New("unsupported")
+	// ErrTypeMismatch signals a value, that does not match the key type of the hashmap.
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrIterating signals a structural change of a hashmap during `EachMut`.
+	ErrIterating = errors.New("structural change during iteration")
+	// ErrInconsistent signals a hasher, which does not match the equality of the keys.
+	ErrInconsistent = errors.New("inconsistent hash and equality")
+)
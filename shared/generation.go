@@ -0,0 +1,58 @@
+package shared
+
+// Generations stamps the buckets of an open addressing hashmap with the
+// generation, in which they were written last. Buckets with a stamp of an
+// older generation are empty, so a hashmap is cleared in O(1) by `Next`
+// instead of walking the buckets. The zero value disables the stamps.
+type Generations struct {
+	stamps  []uint8
+	current uint8
+}
+
+// NewGenerations creates the stamps for `n` buckets,
+// which all belong to the current generation.
+func NewGenerations(n uintptr) Generations {
+	return Generations{stamps: make([]uint8, n)}
+}
+
+// Enabled returns true, if the buckets are stamped.
+//
+//go:inline
+func (g *Generations) Enabled() bool {
+	return g.stamps != nil
+}
+
+// Stale returns true, if the i-th bucket belongs to an older generation.
+//
+//go:inline
+func (g *Generations) Stale(i uintptr) bool {
+	return g.stamps != nil && g.stamps[i] != g.current
+}
+
+// Stamp moves the i-th bucket into the current generation.
+//
+//go:inline
+func (g *Generations) Stamp(i uintptr) {
+	if g.stamps != nil {
+		g.stamps[i] = g.current
+	}
+}
+
+// Next starts a new generation, which makes all buckets stale. On wraparound
+// of the counter the stamps are wiped, otherwise buckets of the generation
+// 256 calls ago would become valid again.
+func (g *Generations) Next() {
+	if g.current++; g.current == 0 {
+		clear(g.stamps)
+		g.current = 1
+	}
+}
+
+// Copy returns a copy of the stamps of the buckets in range [lo,hi).
+func (g *Generations) Copy(lo, hi uintptr) Generations {
+	if g.stamps == nil {
+		return Generations{}
+	}
+
+	return Generations{stamps: append([]uint8(nil), g.stamps[lo:hi]...), current: g.current}
+}
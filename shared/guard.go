@@ -0,0 +1,27 @@
+package shared
+
+// Guard rejects structural changes of a hashmap, while `EachMut` hands out
+// pointers to the values. The zero value is ready to use.
+type Guard struct {
+	// depth counts the active iterations, which may be nested
+	depth int
+}
+
+// Enter marks the begin of an iteration.
+func (g *Guard) Enter() {
+	g.depth++
+}
+
+// Leave marks the end of an iteration.
+func (g *Guard) Leave() {
+	g.depth--
+}
+
+// Check panics with ErrIterating, if an iteration is active.
+//
+//go:inline
+func (g *Guard) Check() {
+	if g.depth != 0 {
+		panic(ErrIterating)
+	}
+}
@@ -16,27 +16,20 @@ func GetHasher[Key any]() HashFn[Key] {
 	kind := reflect.ValueOf(&key).Elem().Type().Kind()
 
 	switch kind {
-	case reflect.Int, reflect.Uint, reflect.Uintptr:
+	case reflect.Int, reflect.Uint, reflect.Uintptr,
+		reflect.Int8, reflect.Uint8, reflect.Int16, reflect.Uint16,
+		reflect.Int32, reflect.Uint32, reflect.Int64, reflect.Uint64:
+		// the size of int, uint and uintptr depends on the architecture
 		switch unsafe.Sizeof(key) {
+		case 1:
+			return *(*func(Key) uintptr)(unsafe.Pointer(&hashByte))
 		case 2:
 			return *(*func(Key) uintptr)(unsafe.Pointer(&hashWord))
 		case 4:
 			return *(*func(Key) uintptr)(unsafe.Pointer(&hashDword))
-		case 8:
-			return *(*func(Key) uintptr)(unsafe.Pointer(&hashQword))
-
 		default:
-			panic("unsupported integer byte size")
+			return *(*func(Key) uintptr)(unsafe.Pointer(&hashQword))
 		}
-
-	case reflect.Int8, reflect.Uint8:
-		return *(*func(Key) uintptr)(unsafe.Pointer(&hashByte))
-	case reflect.Int16, reflect.Uint16:
-		return *(*func(Key) uintptr)(unsafe.Pointer(&hashWord))
-	case reflect.Int32, reflect.Uint32:
-		return *(*func(Key) uintptr)(unsafe.Pointer(&hashDword))
-	case reflect.Int64, reflect.Uint64:
-		return *(*func(Key) uintptr)(unsafe.Pointer(&hashQword))
 	case reflect.Float32:
 		return *(*func(Key) uintptr)(unsafe.Pointer(&hashFloat32))
 	case reflect.Float64:
@@ -91,7 +84,7 @@ var hashFloat64 = func(in float64) uintptr {
 	key ^= (key >> 33)
 	key *= 0xc4ceb9fe1a85ec53
 	key ^= (key >> 33)
-	return uintptr(key)
+	return Fold(key)
 }
 
 // hashQword implements MurmurHash3's 64-bit Finalizer.
@@ -101,12 +94,21 @@ var hashQword = func(key uint64) uintptr {
 	key ^= (key >> 33)
 	key *= 0xc4ceb9fe1a85ec53
 	key ^= (key >> 33)
-	return uintptr(key)
+	return Fold(key)
+}
+
+// fnv1aModified is the string hasher, see `fnv1a`.
+var fnv1aModified = func(s string) uintptr {
+	return Fold(fnv1a(s))
 }
 
-// fnv1aModified implements a simpler and faster variant of the fnv1a algorithm,
+// fnv1a implements a simpler and faster variant of the fnv1a algorithm,
 // that seems good enough for string hashing.
-var fnv1aModified = func(b []byte) uintptr {
+// The string is converted to a slice with a valid capacity, because the slice
+// expressions depend on it.
+func fnv1a(s string) uint64 {
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+
 	const prime64 = uint64(1099511628211)
 	h := uint64(14695981039346656037)
 
@@ -131,5 +133,5 @@ var fnv1aModified = func(b []byte) uintptr {
 		h = (h ^ uint64(b[0])) * prime64
 	}
 
-	return uintptr(h)
+	return h
 }
@@ -0,0 +1,38 @@
+package shared_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+func TestStringHasher(t *testing.T) {
+	var (
+		hasher = shared.GetHasher[string]()
+		data   = strings.Repeat("0123456789abcdef", 8)
+	)
+
+	// the hash value depends only on the content, not on the backing
+	// memory or the capacity of the string
+	for n := 0; n <= len(data); n++ {
+		var (
+			s    = data[:n]
+			want = hasher(s)
+		)
+
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, want, hasher(strings.Clone(s)), "length %d", n)
+			assert.Equal(t, want, hasher(string([]byte(s))), "length %d", n)
+		}
+
+		if n > 0 {
+			// a change of a single byte must change the hash value
+			changed := []byte(s)
+			changed[n-1]++
+			assert.NotEqual(t, want, hasher(string(changed)), "length %d", n)
+		}
+	}
+}
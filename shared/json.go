@@ -0,0 +1,239 @@
+package shared
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// MarshalJSON encodes the key-value pairs as JSON object. The keys must be
+// strings, integers or implement `encoding.TextMarshaler`, which follows the
+// rules of `encoding/json` for golang maps. If `sortKeys` is set, the keys
+// are sorted for a reproducible output, otherwise the order of 'each' is kept.
+func MarshalJSON[K comparable, V any](each func(fn func(key K, val V) bool), sortKeys bool) ([]byte, error) {
+	type member struct {
+		key string
+		val []byte
+	}
+
+	var (
+		buf     bytes.Buffer
+		members []member
+		err     error
+	)
+
+	buf.WriteByte('{')
+
+	each(func(key K, val V) bool {
+		var (
+			k string
+			v []byte
+		)
+
+		if k, err = encodeKey(key); err != nil {
+			return true
+		}
+
+		if v, err = json.Marshal(val); err != nil {
+			return true
+		}
+
+		if sortKeys {
+			members = append(members, member{key: k, val: v})
+		} else {
+			err = writeMember(&buf, k, v)
+		}
+
+		return err != nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].key < members[j].key })
+
+	for i := range members {
+		if err := writeMember(&buf, members[i].key, members[i].val); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func writeMember(buf *bytes.Buffer, key string, val []byte) error {
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(val)
+
+	return nil
+}
+
+// UnmarshalJSON decodes a JSON object and puts each member into the hashmap.
+// The hashmap is sized by 'reserve' for the number of members before.
+// A JSON null is ignored.
+func UnmarshalJSON[K comparable, V any](data []byte, reserve func(n uintptr), put func(key K, val V) bool) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	reserve(countMembers(data))
+
+	return DecodeJSON(json.NewDecoder(bytes.NewReader(data)), put)
+}
+
+// DecodeJSON reads a JSON object from the decoder and puts each member into
+// the hashmap, without decoding the whole object first. See `MarshalJSON`
+// for the supported key types.
+func DecodeJSON[K comparable, V any](dec *json.Decoder, put func(key K, val V) bool) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok != json.Delim('{') {
+		return fmt.Errorf("json: cannot unmarshal %v into a hashmap", tok)
+	}
+
+	for dec.More() {
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+
+		key, err := decodeKey[K](tok.(string))
+		if err != nil {
+			return err
+		}
+
+		var val V
+		if err := dec.Decode(&val); err != nil {
+			return err
+		}
+
+		put(key, val)
+	}
+
+	// consume the closing '}'
+	_, err = dec.Token()
+
+	return err
+}
+
+// countMembers returns the number of members of the top level JSON object.
+// It expects valid JSON, otherwise the result is only an estimation.
+func countMembers(data []byte) uintptr {
+	var (
+		n        uintptr
+		depth    int
+		inString bool
+		escaped  bool
+		empty    = true
+	)
+
+	for _, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			empty = false
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 1:
+			n++
+		}
+	}
+
+	if empty {
+		return 0
+	}
+
+	return n + 1
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func encodeKey[K comparable](key K) (string, error) {
+	rv := reflect.ValueOf(&key).Elem()
+
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+
+	if rv.Type().Implements(textMarshalerType) {
+		text, err := any(key).(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	default:
+		return "", &json.UnsupportedTypeError{Type: rv.Type()}
+	}
+}
+
+func decodeKey[K comparable](s string) (K, error) {
+	var (
+		key K
+		rv  = reflect.ValueOf(&key).Elem()
+	)
+
+	if rv.Kind() != reflect.String && reflect.PointerTo(rv.Type()).Implements(textUnmarshalerType) {
+		err := any(&key).(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		return key, err
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return key, &json.UnmarshalTypeError{Value: "number " + s, Type: rv.Type()}
+		}
+
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return key, &json.UnmarshalTypeError{Value: "number " + s, Type: rv.Type()}
+		}
+
+		rv.SetUint(n)
+	default:
+		return key, &json.UnsupportedTypeError{Type: rv.Type()}
+	}
+
+	return key, nil
+}
@@ -0,0 +1,69 @@
+package shared
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Layout specifies how the open addressing hashmaps organize
+// their buckets in memory.
+type Layout int
+
+const (
+	// AutoLayout uses `SoA` if the size of a value exceeds `SoAValueSize`,
+	// otherwise `AoS`.
+	AutoLayout Layout = iota
+	// AoS interleaves the metadata, the key and the value in one bucket
+	// (array of structs). This is the best choice for small values, because
+	// a lookup touches only one cache line.
+	AoS
+	// SoA stores the metadata, the keys and the values in separate arrays
+	// (struct of arrays). A probe touches only the metadata and the keys,
+	// which is the better choice for large values.
+	SoA
+)
+
+// SoAValueSize is the size of a value in bytes, from which on `AutoLayout`
+// selects the `SoA` layout.
+const SoAValueSize = 64
+
+// UseSoA returns true, if `l` resolves to the `SoA` layout for values of type V.
+// Returns ErrOutOfRange if `l` is unknown.
+func UseSoA[V any](l Layout) (bool, error) {
+	switch l {
+	case AutoLayout:
+		var v V
+		return unsafe.Sizeof(v) > SoAValueSize, nil
+	case AoS:
+		return false, nil
+	case SoA:
+		return true, nil
+	default:
+		return false, fmt.Errorf("layout %d: %w", l, ErrOutOfRange)
+	}
+}
+
+// Storage selects the layout of the buckets at compile time, so the hashmaps
+// do not branch on the layout for each access of a bucket. The hashmaps keep
+// one implementation per storage and switch between them, see `Layout`.
+type Storage interface {
+	AoSStorage | SoAStorage
+}
+
+type (
+	// AoSStorage is the `Storage` of the `AoS` layout.
+	AoSStorage [0]struct{}
+	// SoAStorage is the `Storage` of the `SoA` layout.
+	SoAStorage [1]struct{}
+)
+
+// IsSoA returns true, if S is `SoAStorage`. Both storages have a different
+// shape, so the result is constant within each instantiation and the
+// compiler removes the branches on it.
+//
+//go:inline
+func IsSoA[S Storage]() bool {
+	var s S
+
+	return len(s) != 0
+}
@@ -1,5 +1,13 @@
 package shared
 
+import (
+	"math"
+	"math/bits"
+)
+
+// MaxCapacity is the largest power of two, that fits into an uintptr.
+const MaxCapacity = 1 << (bits.UintSize - 1)
+
 // NextPowerOf2 is a fast computation of 2^x
 // see: https://stackoverflow.com/questions/466204/rounding-up-to-next-power-of-2
 //
@@ -16,3 +24,33 @@ func NextPowerOf2(i uint64) uint64 {
 
 	return i
 }
+
+// Fold converts a 64 bit hash value into an uintptr. On 32 bit architectures
+// the upper half is folded into the lower half, instead of being truncated.
+//
+//go:inline
+func Fold(h uint64) uintptr {
+	if bits.UintSize == 32 {
+		h ^= h >> 32
+	}
+
+	return uintptr(h)
+}
+
+// Capacity returns the number of buckets as power of two, which store n
+// elements without exceeding the load factor lf. It saturates at `MaxCapacity`
+// instead of overflowing.
+func Capacity(n uintptr, lf float32) uintptr {
+	needed := math.Ceil(float64(n) / float64(lf))
+	if needed >= MaxCapacity {
+		return MaxCapacity
+	}
+
+	return uintptr(NextPowerOf2(uint64(needed)))
+}
+
+// Threshold returns the number of elements, from which on a hashmap with
+// 'capacity' buckets and the load factor lf is resized.
+func Threshold(capacity uintptr, lf float32) uintptr {
+	return uintptr(float64(capacity) * float64(lf))
+}
@@ -2,6 +2,7 @@ package shared_test
 
 import (
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 
@@ -24,3 +25,48 @@ func TestNextPowerOfTwo(t *testing.T) {
 	assert.Equal(t, uint64(1024), shared.NextPowerOf2(1000))
 	assert.Equal(t, uint64(2048), shared.NextPowerOf2(2000))
 }
+
+func TestCapacity(t *testing.T) {
+	assert.Equal(t, uintptr(0), shared.Capacity(0, 0.7))
+	assert.Equal(t, uintptr(16), shared.Capacity(7, 0.7))
+	assert.Equal(t, uintptr(16), shared.Capacity(8, 0.5))
+	assert.Equal(t, uintptr(32), shared.Capacity(9, 0.5))
+	assert.Equal(t, uintptr(1<<21), shared.Capacity(1<<20+1, 0.9))
+
+	// saturates instead of overflowing
+	assert.Equal(t, uintptr(shared.MaxCapacity), shared.Capacity(shared.MaxCapacity/2+1, 0.5))
+	assert.Equal(t, uintptr(shared.MaxCapacity), shared.Capacity(^uintptr(0), 0.99))
+
+	assert.Equal(t, uintptr(11), shared.Threshold(16, 0.7))
+	assert.Equal(t, uintptr(shared.MaxCapacity/2), shared.Threshold(shared.MaxCapacity, 0.5))
+}
+
+func TestFold(t *testing.T) {
+	var (
+		h     = uint64(0x123456789abcdef0)
+		width = 8 * unsafe.Sizeof(uintptr(0))
+	)
+
+	if width == 32 {
+		// the upper half influences the lower bits
+		assert.Equal(t, uint64(0x12345678^0x9abcdef0), uint64(shared.Fold(h)))
+		assert.NotEqual(t, shared.Fold(h), shared.Fold(h^1<<48))
+	} else {
+		assert.Equal(t, h, uint64(shared.Fold(h)))
+	}
+}
+
+func TestHasherUpperBits(t *testing.T) {
+	var (
+		hasher  = shared.GetHasher[uint64]()
+		buckets = make(map[uintptr]struct{})
+	)
+
+	// keys, which differ only in the upper half, must be distributed
+	// over the lower bits on 32 bit architectures as well
+	for i := uint64(0); i < 1024; i++ {
+		buckets[hasher(i<<32)&0xFFFF] = struct{}{}
+	}
+
+	assert.Greater(t, len(buckets), 1000)
+}
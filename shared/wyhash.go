@@ -0,0 +1,89 @@
+package shared
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// wySecret is the default secret of wyhash final version 4.
+var wySecret = [4]uint64{0x2d358dccaa6c78a5, 0x8bb84b93962eacc9, 0x4b33a62ed433d4a3, 0x4d5a2da51de1aa47}
+
+func wyMix(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func wyR3(p []byte, k int) uint64 {
+	return uint64(p[0])<<16 | uint64(p[k>>1])<<8 | uint64(p[k-1])
+}
+
+func wyR4(p []byte) uint64 {
+	return uint64(binary.LittleEndian.Uint32(p))
+}
+
+func wyR8(p []byte) uint64 {
+	return binary.LittleEndian.Uint64(p)
+}
+
+// wySeed prepares the seed, it is computed once per hasher.
+func wySeed(seed uint64) uint64 {
+	return seed ^ wyMix(seed^wySecret[0], wySecret[1])
+}
+
+// wyHash implements wyhash final version 4 with a prepared seed, see `wySeed`.
+func wyHash(p []byte, seed uint64) uint64 {
+	var (
+		a, b   uint64
+		length = len(p)
+	)
+
+	switch {
+	case length > 16:
+		// i is the offset of the unprocessed bytes
+		i := 0
+
+		if length > 48 {
+			see1, see2 := seed, seed
+
+			for ; length-i > 48; i += 48 {
+				seed = wyMix(wyR8(p[i:])^wySecret[1], wyR8(p[i+8:])^seed)
+				see1 = wyMix(wyR8(p[i+16:])^wySecret[2], wyR8(p[i+24:])^see1)
+				see2 = wyMix(wyR8(p[i+32:])^wySecret[3], wyR8(p[i+40:])^see2)
+			}
+
+			seed ^= see1 ^ see2
+		}
+
+		for ; length-i > 16; i += 16 {
+			seed = wyMix(wyR8(p[i:])^wySecret[1], wyR8(p[i+8:])^seed)
+		}
+
+		// the last 16 bytes may overlap with the processed ones
+		a = wyR8(p[length-16:])
+		b = wyR8(p[length-8:])
+	case length >= 4:
+		off := (length >> 3) << 2
+		a = wyR4(p)<<32 | wyR4(p[off:])
+		b = wyR4(p[length-4:])<<32 | wyR4(p[length-4-off:])
+	case length > 0:
+		a = wyR3(p, length)
+	}
+
+	a ^= wySecret[1]
+	b ^= seed
+	b, a = bits.Mul64(a, b)
+
+	return wyMix(a^wySecret[0]^uint64(length), b^wySecret[1])
+}
+
+// wyHash64 is `wyHash` of the 8 little endian bytes of the key.
+func wyHash64(key, seed uint64) uint64 {
+	a := key<<32 | key>>32
+	b := key
+
+	a ^= wySecret[1]
+	b ^= seed
+	b, a = bits.Mul64(a, b)
+
+	return wyMix(a^wySecret[0]^8, b^wySecret[1])
+}
@@ -0,0 +1,232 @@
+package shared
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	xxPrime32_1 = 0x9E3779B1
+	xxPrime32_2 = 0x85EBCA77
+	xxPrime32_3 = 0xC2B2AE3D
+	xxPrime64_1 = 0x9E3779B185EBCA87
+	xxPrime64_2 = 0xC2B2AE3D27D4EB4F
+	xxPrime64_3 = 0x165667B19E3779F9
+	xxPrime64_4 = 0x85EBCA77C2B2AE63
+	xxPrime64_5 = 0x27D4EB2F165667C5
+	xxPrimeMx1  = 0x165667919E3779F9
+	xxPrimeMx2  = 0x9FB21C651E98DF25
+
+	xxStripeLen    = 64
+	xxSecretSize   = 192
+	xxStripes      = (xxSecretSize - xxStripeLen) / 8
+	xxBlockLen     = xxStripeLen * xxStripes
+	xxMidSizeMax   = 240
+	xxMidSizeStart = 3
+	xxMidSizeLast  = 17
+	xxLastAccStart = 7
+	xxMergeStart   = 11
+)
+
+// xxSecret is the default secret of XXH3.
+var xxSecret = [xxSecretSize]byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+func xxR32(p []byte) uint64 {
+	return uint64(binary.LittleEndian.Uint32(p))
+}
+
+func xxR64(p []byte) uint64 {
+	return binary.LittleEndian.Uint64(p)
+}
+
+func xxMul128Fold64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func xxh64Avalanche(h uint64) uint64 {
+	h ^= h >> 33
+	h *= xxPrime64_2
+	h ^= h >> 29
+	h *= xxPrime64_3
+	h ^= h >> 32
+
+	return h
+}
+
+func xxh3Avalanche(h uint64) uint64 {
+	h ^= h >> 37
+	h *= xxPrimeMx1
+	h ^= h >> 32
+
+	return h
+}
+
+func xxRrmxmx(h uint64, length int) uint64 {
+	h ^= bits.RotateLeft64(h, 49) ^ bits.RotateLeft64(h, 24)
+	h *= xxPrimeMx2
+	h ^= (h >> 35) + uint64(length)
+	h *= xxPrimeMx2
+	h ^= h >> 28
+
+	return h
+}
+
+func xxMix16(p, secret []byte, seed uint64) uint64 {
+	return xxMul128Fold64(xxR64(p)^(xxR64(secret)+seed), xxR64(p[8:])^(xxR64(secret[8:])-seed))
+}
+
+// xxh3 implements the 64 bit variant of XXH3 with a seed.
+func xxh3(p []byte, seed uint64) uint64 {
+	length := len(p)
+
+	switch {
+	case length == 0:
+		return xxh64Avalanche(seed ^ xxR64(xxSecret[56:]) ^ xxR64(xxSecret[64:]))
+	case length < 4:
+		var (
+			combined = uint64(p[0])<<16 | uint64(p[length>>1])<<24 | uint64(p[length-1]) | uint64(length)<<8
+			flip     = (xxR32(xxSecret[:]) ^ xxR32(xxSecret[4:])) + seed
+		)
+
+		return xxh64Avalanche(combined ^ flip)
+	case length <= 8:
+		return xxh3Len4To8(xxR32(p), xxR32(p[length-4:]), length, seed)
+	case length <= 16:
+		var (
+			lo = xxR64(p) ^ ((xxR64(xxSecret[24:]) ^ xxR64(xxSecret[32:])) + seed)
+			hi = xxR64(p[length-8:]) ^ ((xxR64(xxSecret[40:]) ^ xxR64(xxSecret[48:])) - seed)
+		)
+
+		return xxh3Avalanche(uint64(length) + bits.ReverseBytes64(lo) + hi + xxMul128Fold64(lo, hi))
+	case length <= 128:
+		acc := uint64(length) * xxPrime64_1
+
+		if length > 32 {
+			if length > 64 {
+				if length > 96 {
+					acc += xxMix16(p[48:], xxSecret[96:], seed)
+					acc += xxMix16(p[length-64:], xxSecret[112:], seed)
+				}
+
+				acc += xxMix16(p[32:], xxSecret[64:], seed)
+				acc += xxMix16(p[length-48:], xxSecret[80:], seed)
+			}
+
+			acc += xxMix16(p[16:], xxSecret[32:], seed)
+			acc += xxMix16(p[length-32:], xxSecret[48:], seed)
+		}
+
+		acc += xxMix16(p, xxSecret[:], seed)
+		acc += xxMix16(p[length-16:], xxSecret[16:], seed)
+
+		return xxh3Avalanche(acc)
+	case length <= xxMidSizeMax:
+		acc := uint64(length) * xxPrime64_1
+
+		for i := 0; i < 8; i++ {
+			acc += xxMix16(p[16*i:], xxSecret[16*i:], seed)
+		}
+
+		acc = xxh3Avalanche(acc)
+
+		for i := 8; i < length/16; i++ {
+			acc += xxMix16(p[16*i:], xxSecret[16*(i-8)+xxMidSizeStart:], seed)
+		}
+
+		acc += xxMix16(p[length-16:], xxSecret[136-xxMidSizeLast:], seed)
+
+		return xxh3Avalanche(acc)
+	default:
+		return xxh3Long(p, seed)
+	}
+}
+
+// xxh3Len4To8 hashes 4 to 8 bytes, which are given by the first and the last 4 bytes.
+func xxh3Len4To8(first, last uint64, length int, seed uint64) uint64 {
+	seed ^= uint64(bits.ReverseBytes32(uint32(seed))) << 32
+
+	flip := (xxR64(xxSecret[8:]) ^ xxR64(xxSecret[16:])) - seed
+
+	return xxRrmxmx((last+first<<32)^flip, length)
+}
+
+// xxh3Long hashes more than 240 bytes by stripes of 64 bytes.
+func xxh3Long(p []byte, seed uint64) uint64 {
+	var (
+		secret = xxSecret
+		acc    = [8]uint64{
+			xxPrime32_3, xxPrime64_1, xxPrime64_2, xxPrime64_3,
+			xxPrime64_4, xxPrime32_2, xxPrime64_5, xxPrime32_1,
+		}
+		length = len(p)
+		blocks = (length - 1) / xxBlockLen
+	)
+
+	if seed != 0 {
+		for i := 0; i < xxSecretSize; i += 16 {
+			binary.LittleEndian.PutUint64(secret[i:], xxR64(xxSecret[i:])+seed)
+			binary.LittleEndian.PutUint64(secret[i+8:], xxR64(xxSecret[i+8:])-seed)
+		}
+	}
+
+	for n := 0; n < blocks; n++ {
+		block := p[n*xxBlockLen:]
+
+		for s := 0; s < xxStripes; s++ {
+			xxAccumulate(&acc, block[s*xxStripeLen:], secret[s*8:])
+		}
+
+		// scramble
+		for i := range acc {
+			acc[i] ^= acc[i] >> 47
+			acc[i] ^= xxR64(secret[xxSecretSize-xxStripeLen+8*i:])
+			acc[i] *= xxPrime32_1
+		}
+	}
+
+	var (
+		last    = p[blocks*xxBlockLen:]
+		stripes = ((length - 1) - xxBlockLen*blocks) / xxStripeLen
+	)
+
+	for s := 0; s < stripes; s++ {
+		xxAccumulate(&acc, last[s*xxStripeLen:], secret[s*8:])
+	}
+
+	xxAccumulate(&acc, p[length-xxStripeLen:], secret[xxSecretSize-xxStripeLen-xxLastAccStart:])
+
+	result := uint64(length) * xxPrime64_1
+
+	for i := 0; i < 4; i++ {
+		merge := secret[xxMergeStart+16*i:]
+		result += xxMul128Fold64(acc[2*i]^xxR64(merge), acc[2*i+1]^xxR64(merge[8:]))
+	}
+
+	return xxh3Avalanche(result)
+}
+
+func xxAccumulate(acc *[8]uint64, p, secret []byte) {
+	for i := range acc {
+		var (
+			val = xxR64(p[8*i:])
+			key = val ^ xxR64(secret[8*i:])
+		)
+
+		acc[i^1] += val
+		acc[i] += (key & 0xFFFFFFFF) * (key >> 32)
+	}
+}
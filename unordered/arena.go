@@ -0,0 +1,111 @@
+package unordered
+
+const (
+	minChunkSize = 8       // size of the first chunk
+	maxChunkSize = 1 << 14 // upper bound for the doubling of the chunk sizes
+)
+
+// arena allocates the nodes of the hashmap from chunks instead of
+// allocating each node separately on the heap. Nodes are never moved,
+// so the pointer to a node is stable until it is released.
+// Released nodes are collected in a free list and reused first.
+type arena[K comparable, V any] struct {
+	chunks [][]node[K, V]
+	// cur is the index of the chunk, where new nodes are taken from
+	cur int
+	// used is the number of taken nodes within the current chunk
+	used int
+	// free is a single linked list of released nodes
+	free *node[K, V]
+}
+
+// alloc returns a zeroed node.
+//
+//go:inline
+func (a *arena[K, V]) alloc() *node[K, V] {
+	if a.free != nil {
+		n := a.free
+		a.free = n.next
+		n.next = nil
+
+		return n
+	}
+
+	if len(a.chunks) == 0 || a.used == len(a.chunks[a.cur]) {
+		a.nextChunk()
+	}
+
+	n := &a.chunks[a.cur][a.used]
+	a.used++
+
+	return n
+}
+
+// nextChunk switches to the next chunk, which is allocated if needed.
+func (a *arena[K, V]) nextChunk() {
+	if len(a.chunks) != 0 {
+		a.cur++
+	}
+
+	a.used = 0
+
+	if a.cur < len(a.chunks) {
+		// reuse an already allocated chunk
+		return
+	}
+
+	size := minChunkSize
+	if len(a.chunks) > 0 {
+		size = 2 * len(a.chunks[len(a.chunks)-1])
+		if size > maxChunkSize {
+			size = maxChunkSize
+		}
+	}
+
+	a.chunks = append(a.chunks, make([]node[K, V], size))
+}
+
+// release puts the node to the free list. The node is zeroed, so that
+// the garbage collector can free the memory referenced by the key-value pair.
+//
+//go:inline
+func (a *arena[K, V]) release(n *node[K, V]) {
+	*n = node[K, V]{next: a.free}
+	a.free = n
+}
+
+// capacity returns the number of nodes of all chunks.
+func (a *arena[K, V]) capacity() uintptr {
+	var c uintptr
+	for i := range a.chunks {
+		c += uintptr(len(a.chunks[i]))
+	}
+
+	return c
+}
+
+// reserve allocates one chunk for the missing nodes, so that at least
+// n nodes fit into the arena.
+func (a *arena[K, V]) reserve(n uintptr) {
+	if c := a.capacity(); c < n {
+		a.chunks = append(a.chunks, make([]node[K, V], n-c))
+	}
+}
+
+// reset releases all nodes at once and keeps the chunks for reuse.
+func (a *arena[K, V]) reset() {
+	for i := 0; i < len(a.chunks) && i <= a.cur; i++ {
+		chunk := a.chunks[i]
+		if i == a.cur {
+			chunk = chunk[:a.used]
+		}
+
+		for j := range chunk {
+			chunk[j] = node[K, V]{}
+		}
+	}
+
+	a.cur = 0
+	a.used = 0
+	a.free = nil
+}
@@ -0,0 +1,354 @@
+package unordered
+
+import (
+	"fmt"
+
+	"github.com/EinfachAndy/hashmaps/shared"
+)
+
+type inlineBucket[K comparable, V any] struct {
+	// next is the chain of the colliding key-value pairs
+	next  *node[K, V]
+	key   K
+	value V
+	used  bool
+}
+
+// Inline is a hashmap with separate chaining like `Unordered`, but the bucket
+// array stores the first key-value pair of each bucket inline and only the
+// colliding pairs are chained. This saves one pointer dereference per lookup.
+// In contrast to `Unordered` the elements are moved, so the pointers returned
+// by `Lookup` and `Insert` are only valid until the next modification of the
+// hashmap. Use `Unordered` if the pointers must be stable.
+type Inline[K comparable, V any] struct {
+	buckets []inlineBucket[K, V]
+	arena   arena[K, V]
+	hasher  shared.HashFn[K]
+	// length stores the current inserted elements
+	length uintptr
+	// capMinus1 is used for a bitwise AND on the hash value,
+	// because the size of the underlying array is a power of two value
+	capMinus1 uintptr
+
+	nextResize uintptr
+	maxLoad    float32
+	// sortKeys sorts the keys of `MarshalJSON`
+	sortKeys bool
+	// guard rejects structural changes during `EachMut`
+	guard shared.Guard
+}
+
+// NewInline creates a ready to use `Inline` hashmap with default settings.
+func NewInline[K comparable, V any]() *Inline[K, V] {
+	return NewInlineWithHasher[K, V](shared.GetHasher[K]())
+}
+
+// NewInlineWithHasher same as `NewInline` but with a given hash function.
+func NewInlineWithHasher[K comparable, V any](hasher shared.HashFn[K]) *Inline[K, V] {
+	m := &Inline[K, V]{
+		hasher:  hasher,
+		maxLoad: shared.DefaultMaxLoad,
+	}
+	m.Reserve(shared.DefaultSize)
+
+	return m
+}
+
+//go:inline
+func (m *Inline[K, V]) search(key K, idx uintptr) *V {
+	bucket := &m.buckets[idx]
+	if !bucket.used {
+		return nil
+	}
+
+	if bucket.key == key {
+		return &(bucket.value)
+	}
+
+	for current := bucket.next; current != nil; current = current.next {
+		if current.key == key {
+			return &(current.value)
+		}
+	}
+
+	return nil
+}
+
+// Get returns the value stored for this key, or false if not found.
+func (m *Inline[K, V]) Get(key K) (V, bool) {
+	var (
+		idx = m.hasher(key) & m.capMinus1
+		v   V
+	)
+
+	ptr := m.search(key, idx)
+	if ptr != nil {
+		return *ptr, true
+	}
+
+	return v, false
+}
+
+// Lookup returns a pointer to the stored value for this key or nil if not found.
+// The pointer is valid until the next modification of the hashmap.
+// Note, use `Get` for small values.
+func (m *Inline[K, V]) Lookup(key K) *V {
+	idx := m.hasher(key) & m.capMinus1
+	return m.search(key, idx)
+}
+
+// Insert returns a pointer to a zero allocated value. These pointer is valid until
+// the next modification of the hashmap. Note, use `Put` for small values.
+func (m *Inline[K, V]) Insert(key K) (*V, bool) {
+	m.guard.Check()
+
+	if m.length >= m.nextResize {
+		m.resize(uintptr(len(m.buckets)) * 2)
+	}
+
+	idx := m.hasher(key) & m.capMinus1
+
+	ptr := m.search(key, idx)
+	if ptr != nil {
+		return ptr, false
+	}
+
+	m.length++
+
+	bucket := &m.buckets[idx]
+	if !bucket.used {
+		bucket.used = true
+		bucket.key = key
+
+		return &bucket.value, true
+	}
+
+	newNode := m.arena.alloc()
+	newNode.key = key
+	newNode.next = bucket.next
+	bucket.next = newNode
+
+	return &newNode.value, true
+}
+
+// emplace moves the key-value pair into the bucket array. The node `n` is
+// reused for a colliding pair, if it is not nil. It does not check
+// if the key is already in.
+//
+//go:inline
+func (m *Inline[K, V]) emplace(key K, val V, n *node[K, V]) {
+	bucket := &m.buckets[m.hasher(key)&m.capMinus1]
+	if !bucket.used {
+		bucket.used = true
+		bucket.key = key
+		bucket.value = val
+
+		if n != nil {
+			m.arena.release(n)
+		}
+
+		return
+	}
+
+	if n == nil {
+		n = m.arena.alloc()
+	}
+
+	n.key = key
+	n.value = val
+	n.next = bucket.next
+	bucket.next = n
+}
+
+func (m *Inline[K, V]) resize(n uintptr) {
+	oldBuckets := m.buckets
+	m.buckets = make([]inlineBucket[K, V], n)
+	m.capMinus1 = n - 1
+	m.nextResize = shared.Threshold(n, m.maxLoad)
+
+	for i := range oldBuckets {
+		if !oldBuckets[i].used {
+			continue
+		}
+
+		// the chain is reused
+		for current := oldBuckets[i].next; current != nil; {
+			next := current.next
+			m.emplace(current.key, current.value, current)
+			current = next
+		}
+
+		m.emplace(oldBuckets[i].key, oldBuckets[i].value, nil)
+	}
+}
+
+// Reserve sets the number of buckets to the most appropriate to contain at least n elements.
+// If n is lower than that, the function may have no effect.
+func (m *Inline[K, V]) Reserve(n uintptr) {
+	m.guard.Check()
+
+	newCap := shared.Capacity(n, m.maxLoad)
+
+	if uintptr(len(m.buckets)) < newCap {
+		m.resize(newCap)
+	}
+}
+
+// Put adds the given key-value pair to the hashmap. If the key already exists its
+// value will be overwritten with the new value.
+// Returns true, if the element is a new item in the hashmap.
+//
+//go:inline
+func (m *Inline[K, V]) Put(key K, val V) bool {
+	v, isNew := m.Insert(key)
+	*v = val
+
+	return isNew
+}
+
+// Remove removes the specified key-value pair from the hashmap.
+// Returns true, if the element was in the hashmap.
+func (m *Inline[K, V]) Remove(key K) bool {
+	m.guard.Check()
+
+	var (
+		idx    = m.hasher(key) & m.capMinus1
+		bucket = &m.buckets[idx]
+	)
+
+	if !bucket.used {
+		return false
+	}
+
+	if bucket.key == key {
+		m.length--
+
+		if first := bucket.next; first != nil {
+			// move the first colliding pair into the bucket
+			bucket.key = first.key
+			bucket.value = first.value
+			bucket.next = first.next
+			m.arena.release(first)
+		} else {
+			*bucket = inlineBucket[K, V]{}
+		}
+
+		return true
+	}
+
+	for prev := &bucket.next; *prev != nil; prev = &(*prev).next {
+		if current := *prev; current.key == key {
+			*prev = current.next
+			m.arena.release(current)
+			m.length--
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Clear removes all key-value pairs from the hashmap.
+func (m *Inline[K, V]) Clear() {
+	m.guard.Check()
+
+	for i := range m.buckets {
+		m.buckets[i] = inlineBucket[K, V]{}
+	}
+
+	m.arena.reset()
+	m.length = 0
+}
+
+// Size returns the number of items in the hashmap.
+func (m *Inline[K, V]) Size() int {
+	return int(m.length)
+}
+
+// Load return the current load of the hashmap.
+func (m *Inline[K, V]) Load() float32 {
+	return float32(m.length) / float32(len(m.buckets))
+}
+
+// MaxLoad forces resizing if the ratio is reached.
+// Useful values are in range [0.7-1.0].
+// Returns ErrOutOfRange if `lf` is less than or equal zero.
+func (m *Inline[K, V]) MaxLoad(lf float32) error {
+	if lf <= 0.0 {
+		return fmt.Errorf("%f: %w", lf, shared.ErrOutOfRange)
+	}
+
+	m.maxLoad = lf
+	m.nextResize = shared.Threshold(uintptr(len(m.buckets)), lf)
+
+	return nil
+}
+
+// Copy returns a copy of this hashmap.
+func (m *Inline[K, V]) Copy() *Inline[K, V] {
+	newM := &Inline[K, V]{
+		buckets:    make([]inlineBucket[K, V], len(m.buckets)),
+		capMinus1:  m.capMinus1,
+		hasher:     m.hasher,
+		maxLoad:    m.maxLoad,
+		nextResize: m.nextResize,
+		sortKeys:   m.sortKeys,
+	}
+
+	m.Each(func(k K, v V) bool {
+		newM.Put(k, v)
+		return false
+	})
+
+	return newM
+}
+
+// Each calls 'fn' on every key-value pair in the hash map in no particular order.
+// If 'fn' returns true, the iteration stops.
+func (m *Inline[K, V]) Each(fn func(key K, val V) bool) {
+	for i := range m.buckets {
+		if !m.buckets[i].used {
+			continue
+		}
+
+		if stop := fn(m.buckets[i].key, m.buckets[i].value); stop {
+			// stop iteration
+			return
+		}
+
+		for current := m.buckets[i].next; current != nil; current = current.next {
+			if stop := fn(current.key, current.value); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+}
+
+// EachMut calls 'fn' on every key-value pair in the hashmap in no particular order.
+// The value can be modified through the pointer, which is valid until 'fn' returns.
+// Structural changes like `Put`, `Remove` or `Clear` within 'fn' panic with
+// `shared.ErrIterating`. If 'fn' returns true, the iteration stops.
+func (m *Inline[K, V]) EachMut(fn func(key K, val *V) bool) {
+	m.guard.Enter()
+	defer m.guard.Leave()
+
+	for i := range m.buckets {
+		if !m.buckets[i].used {
+			continue
+		}
+
+		if stop := fn(m.buckets[i].key, &m.buckets[i].value); stop {
+			// stop iteration
+			return
+		}
+
+		for current := m.buckets[i].next; current != nil; current = current.next {
+			if stop := fn(current.key, &current.value); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+}
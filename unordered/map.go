@@ -6,8 +6,11 @@ import (
 	"github.com/EinfachAndy/hashmaps/shared"
 )
 
+// linkedList is a bucket of the hashmap. The nodes are either chained in
+// the list `head` or, if the chain became too long, organized in `tree`.
 type linkedList[K comparable, V any] struct {
 	head *node[K, V]
+	tree *tree[K, V]
 }
 
 type node[K comparable, V any] struct {
@@ -20,9 +23,18 @@ type node[K comparable, V any] struct {
 // depending on their hash values. Collisions are chained in a single linked list.
 // An inserted value keeps its memory address, means an element in a bucket will not copied
 // or swapped. That supports holding points instead of copy by value. see: `Insert` and `lookup`.
+// The nodes are allocated in chunks and removed nodes are reused, which
+// reduces the number of heap objects, see `Defragment`.
+// Chains that exceed a threshold are converted into balanced trees,
+// so the lookup time is bounded by O(log(n)) even for a bad hasher.
 type Unordered[K comparable, V any] struct {
 	buckets []linkedList[K, V]
+	arena   arena[K, V]
 	hasher  shared.HashFn[K]
+	// less orders the keys within a tree, nil if the key type is not ordered
+	less func(a, b K) bool
+	// trees is the number of buckets, which are organized as tree
+	trees uintptr
 	// length stores the current inserted elements
 	length uintptr
 	// capMinus1 is used for a bitwise AND on the hash value,
@@ -31,6 +43,10 @@ type Unordered[K comparable, V any] struct {
 
 	nextResize uintptr
 	maxLoad    float32
+	// sortKeys sorts the keys of `MarshalJSON`
+	sortKeys bool
+	// guard rejects structural changes during `EachMut`
+	guard shared.Guard
 }
 
 // New creates a ready to use `unordered` hashmap with default settings.
@@ -42,6 +58,7 @@ func New[K comparable, V any]() *Unordered[K, V] {
 func NewWithHasher[K comparable, V any](hasher shared.HashFn[K]) *Unordered[K, V] {
 	m := &Unordered[K, V]{
 		hasher:  hasher,
+		less:    orderedLess[K](),
 		maxLoad: shared.DefaultMaxLoad,
 	}
 	m.Reserve(shared.DefaultSize)
@@ -50,8 +67,17 @@ func NewWithHasher[K comparable, V any](hasher shared.HashFn[K]) *Unordered[K, V
 }
 
 //go:inline
-func (m *Unordered[K, V]) search(key K, idx uintptr) *V {
-	for current := m.buckets[idx].head; current != nil; current = current.next {
+func (m *Unordered[K, V]) search(key K, hash uintptr) *V {
+	bucket := &m.buckets[hash&m.capMinus1]
+	if bucket.tree != nil {
+		if n := bucket.tree.search(hash, key); n != nil {
+			return &(n.value)
+		}
+
+		return nil
+	}
+
+	for current := bucket.head; current != nil; current = current.next {
 		if current.key == key {
 			return &(current.value)
 		}
@@ -61,12 +87,9 @@ func (m *Unordered[K, V]) search(key K, idx uintptr) *V {
 
 // Get returns the value stored for this key, or false if not found.
 func (m *Unordered[K, V]) Get(key K) (V, bool) {
-	var (
-		idx = m.hasher(key) & m.capMinus1
-		v   V
-	)
+	var v V
 
-	ptr := m.search(key, idx)
+	ptr := m.search(key, m.hasher(key))
 	if ptr != nil {
 		return *ptr, true
 	}
@@ -78,8 +101,7 @@ func (m *Unordered[K, V]) Get(key K) (V, bool) {
 // The pointer is valid until the key is part of the hashmap.
 // Note, use `Get` for small values.
 func (m *Unordered[K, V]) Lookup(key K) *V {
-	idx := m.hasher(key) & m.capMinus1
-	return m.search(key, idx)
+	return m.search(key, m.hasher(key))
 }
 
 //go:inline
@@ -91,32 +113,75 @@ func (m *Unordered[K, V]) pushFront(head **node[K, V], newNode *node[K, V]) {
 // Insert returns a pointer to a zero allocated value. These pointer is valid until
 // the key is part of the hashmap. Note, use `Put` for small values.
 func (m *Unordered[K, V]) Insert(key K) (*V, bool) {
+	m.guard.Check()
+
 	if m.length >= m.nextResize {
 		m.grow()
 	}
 
-	idx := m.hasher(key) & m.capMinus1
+	hash := m.hasher(key)
 
-	ptr := m.search(key, idx)
+	ptr := m.search(key, hash)
 	if ptr != nil {
 		return ptr, false
 	}
 
 	m.length++
-	newNode := &node[K, V]{key: key}
-	m.pushFront(&(m.buckets[idx].head), newNode)
+	newNode := m.arena.alloc()
+	newNode.key = key
+
+	bucket := &m.buckets[hash&m.capMinus1]
+	if bucket.tree != nil {
+		bucket.tree.insert(hash, newNode)
+	} else {
+		m.pushFront(&(bucket.head), newNode)
+		m.treeify(bucket)
+	}
 
 	return &newNode.value, true
 }
 
+// treeify converts the chain of the bucket into a tree, if it is too long.
+//
+//go:inline
+func (m *Unordered[K, V]) treeify(bucket *linkedList[K, V]) {
+	n := 0
+	for current := bucket.head; current != nil; current = current.next {
+		if n++; n > treeifyThreshold {
+			bucket.tree = newTree(bucket.head, m.hasher, m.less)
+			bucket.head = nil
+			m.trees++
+
+			return
+		}
+	}
+}
+
+// detach removes all nodes from the bucket and returns them as chain.
+//
+//go:inline
+func (m *Unordered[K, V]) detach(bucket *linkedList[K, V]) *node[K, V] {
+	head := bucket.head
+	if bucket.tree != nil {
+		head = bucket.tree.toList()
+		bucket.tree = nil
+		m.trees--
+	}
+
+	bucket.head = nil
+
+	return head
+}
+
 func (m *Unordered[K, V]) resize(n uintptr) {
 	m.capMinus1 = n - 1
 	oldBuckets := m.buckets
 	m.buckets = make([]linkedList[K, V], n)
-	m.nextResize = uintptr(float32(n) * m.maxLoad)
+	m.nextResize = shared.Threshold(n, m.maxLoad)
+	hadTrees := m.trees > 0
 
 	for i := range oldBuckets {
-		for current := oldBuckets[i].head; current != nil; {
+		for current := m.detach(&oldBuckets[i]); current != nil; {
 			newElem := current
 			current = current.next
 			newElem.next = nil // unlink from old
@@ -126,14 +191,27 @@ func (m *Unordered[K, V]) resize(n uintptr) {
 			m.pushFront(&(m.buckets[newIdx].head), newElem)
 		}
 	}
+
+	if hadTrees {
+		// long chains can only occur if there were trees before
+		for i := range m.buckets {
+			m.treeify(&m.buckets[i])
+		}
+	}
 }
 
 // Clear removes all key-value pairs from the hashmap.
+// The allocated nodes are kept for reuse.
 func (m *Unordered[K, V]) Clear() {
+	m.guard.Check()
+
 	for i := range m.buckets {
 		m.buckets[i].head = nil
+		m.buckets[i].tree = nil
 	}
 
+	m.trees = 0
+	m.arena.reset()
 	m.length = 0
 }
 
@@ -155,14 +233,15 @@ func (m *Unordered[K, V]) grow() {
 // Reserve sets the number of buckets to the most appropriate to contain at least n elements.
 // If n is lower than that, the function may have no effect.
 func (m *Unordered[K, V]) Reserve(n uintptr) {
-	var (
-		needed = uintptr(float32(n) / m.maxLoad)
-		newCap = uintptr(shared.NextPowerOf2(uint64(needed)))
-	)
+	m.guard.Check()
+
+	newCap := shared.Capacity(n, m.maxLoad)
 
 	if uintptr(cap(m.buckets)) < newCap {
 		m.resize(newCap)
 	}
+
+	m.arena.reserve(n)
 }
 
 // Put adds the given key-value pair to the hashmap. If the key already exists its
@@ -180,15 +259,35 @@ func (m *Unordered[K, V]) Put(key K, val V) bool {
 // Remove removes the specified key-value pair from the hashmap.
 // Returns true, if the element was in the hashmap.
 func (m *Unordered[K, V]) Remove(key K) bool {
+	m.guard.Check()
+
 	var (
-		idx     = m.hasher(key) & m.capMinus1
+		hash    = m.hasher(key)
+		idx     = hash & m.capMinus1
 		current = m.buckets[idx].head
 		prev    *node[K, V]
 	)
 
+	if t := m.buckets[idx].tree; t != nil {
+		removed := t.remove(hash, key)
+		if removed == nil {
+			return false
+		}
+
+		m.arena.release(removed)
+		m.length--
+
+		if t.size <= untreeifyThreshold {
+			m.buckets[idx].head = m.detach(&m.buckets[idx])
+		}
+
+		return true
+	}
+
 	// check head
 	if current != nil && current.key == key {
 		m.buckets[idx].head = current.next
+		m.arena.release(current)
 		m.length--
 
 		return true
@@ -206,6 +305,7 @@ func (m *Unordered[K, V]) Remove(key K) bool {
 
 	// unlink
 	prev.next = current.next
+	m.arena.release(current)
 	m.length--
 
 	return true
@@ -214,11 +314,15 @@ func (m *Unordered[K, V]) Remove(key K) bool {
 // Copy returns a copy of this hashmap.
 func (m *Unordered[K, V]) Copy() *Unordered[K, V] {
 	newM := &Unordered[K, V]{
-		buckets:   make([]linkedList[K, V], cap(m.buckets)),
-		capMinus1: m.capMinus1,
-		length:    m.length,
-		hasher:    m.hasher,
+		buckets:    make([]linkedList[K, V], cap(m.buckets)),
+		capMinus1:  m.capMinus1,
+		hasher:     m.hasher,
+		less:       m.less,
+		maxLoad:    m.maxLoad,
+		nextResize: m.nextResize,
+		sortKeys:   m.sortKeys,
 	}
+	newM.arena.reserve(m.length)
 
 	m.Each(func(k K, v V) bool {
 		newM.Put(k, v)
@@ -228,6 +332,40 @@ func (m *Unordered[K, V]) Copy() *Unordered[K, V] {
 	return newM
 }
 
+// Defragment moves all nodes into one contiguous chunk of memory and
+// frees the memory of the removed nodes. All pointers returned by
+// `Lookup` and `Insert` become invalid, so it must be called only if
+// the caller holds no such pointers.
+func (m *Unordered[K, V]) Defragment() {
+	m.guard.Check()
+
+	var a arena[K, V]
+	a.reserve(m.length)
+
+	for i := range m.buckets {
+		var (
+			head     *node[K, V]
+			wasTree  = m.buckets[i].tree != nil
+			detached = m.detach(&m.buckets[i])
+		)
+
+		for current := detached; current != nil; current = current.next {
+			newNode := a.alloc()
+			newNode.key = current.key
+			newNode.value = current.value
+			m.pushFront(&head, newNode)
+		}
+
+		m.buckets[i].head = head
+
+		if wasTree {
+			m.treeify(&m.buckets[i])
+		}
+	}
+
+	m.arena = a
+}
+
 // MaxLoad forces resizing if the ratio is reached.
 // Useful values are in range [0.7-1.0].
 // Returns ErrOutOfRange if `lf` is less than or equal zero.
@@ -237,7 +375,7 @@ func (m *Unordered[K, V]) MaxLoad(lf float32) error {
 	}
 
 	m.maxLoad = lf
-	m.nextResize = uintptr(float32(cap(m.buckets)) * lf)
+	m.nextResize = shared.Threshold(uintptr(cap(m.buckets)), lf)
 
 	return nil
 }
@@ -246,6 +384,15 @@ func (m *Unordered[K, V]) MaxLoad(lf float32) error {
 // If 'fn' returns true, the iteration stops.
 func (m *Unordered[K, V]) Each(fn func(key K, val V) bool) {
 	for i := range m.buckets {
+		if t := m.buckets[i].tree; t != nil {
+			if t.root.each(func(e *node[K, V]) bool { return fn(e.key, e.value) }) {
+				// stop iteration
+				return
+			}
+
+			continue
+		}
+
 		for current := m.buckets[i].head; current != nil; current = current.next {
 			if stop := fn(current.key, current.value); stop {
 				// stop iteration
@@ -254,3 +401,30 @@ func (m *Unordered[K, V]) Each(fn func(key K, val V) bool) {
 		}
 	}
 }
+
+// EachMut calls 'fn' on every key-value pair in the hashmap in no particular order.
+// The value can be modified through the pointer, which is valid until 'fn' returns.
+// Structural changes like `Put`, `Remove` or `Clear` within 'fn' panic with
+// `shared.ErrIterating`. If 'fn' returns true, the iteration stops.
+func (m *Unordered[K, V]) EachMut(fn func(key K, val *V) bool) {
+	m.guard.Enter()
+	defer m.guard.Leave()
+
+	for i := range m.buckets {
+		if t := m.buckets[i].tree; t != nil {
+			if t.root.each(func(e *node[K, V]) bool { return fn(e.key, &e.value) }) {
+				// stop iteration
+				return
+			}
+
+			continue
+		}
+
+		for current := m.buckets[i].head; current != nil; current = current.next {
+			if stop := fn(current.key, &current.value); stop {
+				// stop iteration
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package unordered
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+const (
+	// treeifyThreshold is the length of a chain, from which on
+	// the chain is converted into a tree.
+	treeifyThreshold = 8
+	// untreeifyThreshold is the size of a tree, from which on
+	// the tree is converted back into a chain.
+	untreeifyThreshold = 6
+)
+
+// tree is a balanced search tree (AVL) over the nodes of one bucket.
+// It bounds the lookup time for long chains, e.g.: caused by a bad hasher.
+// The tree is ordered by the full hash value and additionally by the key,
+// if the key type is ordered.
+type tree[K comparable, V any] struct {
+	root *treeNode[K, V]
+	size uintptr
+	less func(a, b K) bool
+}
+
+type treeNode[K comparable, V any] struct {
+	left, right *treeNode[K, V]
+	// entries is a chain of all nodes that have the same order,
+	// it is linked by the `next` pointers of the nodes.
+	entries *node[K, V]
+	hash    uintptr
+	height  int8
+}
+
+// orderedLess returns a less function for ordered key types or nil.
+func orderedLess[K comparable]() func(a, b K) bool {
+	var (
+		key  K
+		kind = reflect.ValueOf(&key).Elem().Type().Kind()
+	)
+
+	switch kind {
+	case reflect.Int:
+		return castLess[K](func(a, b int) bool { return a < b })
+	case reflect.Int8:
+		return castLess[K](func(a, b int8) bool { return a < b })
+	case reflect.Int16:
+		return castLess[K](func(a, b int16) bool { return a < b })
+	case reflect.Int32:
+		return castLess[K](func(a, b int32) bool { return a < b })
+	case reflect.Int64:
+		return castLess[K](func(a, b int64) bool { return a < b })
+	case reflect.Uint:
+		return castLess[K](func(a, b uint) bool { return a < b })
+	case reflect.Uint8:
+		return castLess[K](func(a, b uint8) bool { return a < b })
+	case reflect.Uint16:
+		return castLess[K](func(a, b uint16) bool { return a < b })
+	case reflect.Uint32:
+		return castLess[K](func(a, b uint32) bool { return a < b })
+	case reflect.Uint64:
+		return castLess[K](func(a, b uint64) bool { return a < b })
+	case reflect.Uintptr:
+		return castLess[K](func(a, b uintptr) bool { return a < b })
+	case reflect.Float32:
+		return castLess[K](func(a, b float32) bool { return a < b })
+	case reflect.Float64:
+		return castLess[K](func(a, b float64) bool { return a < b })
+	case reflect.String:
+		return castLess[K](func(a, b string) bool { return a < b })
+	default:
+		return nil
+	}
+}
+
+// castLess converts the less function of the underlying basic type T
+// to the key type K, both have the same memory layout.
+func castLess[K, T any](less func(a, b T) bool) func(a, b K) bool {
+	return *(*func(a, b K) bool)(unsafe.Pointer(&less))
+}
+
+// newTree converts the chain `head` into a tree.
+func newTree[K comparable, V any](head *node[K, V], hasher func(K) uintptr, less func(a, b K) bool) *tree[K, V] {
+	t := &tree[K, V]{less: less}
+
+	for current := head; current != nil; {
+		next := current.next
+		t.insert(hasher(current.key), current)
+		current = next
+	}
+
+	return t
+}
+
+// compare orders (hash, key) relative to the tree node `n`.
+//
+//go:inline
+func (t *tree[K, V]) compare(hash uintptr, key K, n *treeNode[K, V]) int {
+	switch {
+	case hash < n.hash:
+		return -1
+	case hash > n.hash:
+		return 1
+	case t.less == nil:
+		return 0
+	case t.less(key, n.entries.key):
+		return -1
+	case t.less(n.entries.key, key):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// search returns the node of the key or nil.
+func (t *tree[K, V]) search(hash uintptr, key K) *node[K, V] {
+	for n := t.root; n != nil; {
+		switch c := t.compare(hash, key, n); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			for e := n.entries; e != nil; e = e.next {
+				if e.key == key {
+					return e
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// insert adds the node `e`. It expects that the key is not already in.
+func (t *tree[K, V]) insert(hash uintptr, e *node[K, V]) {
+	t.root = t.insertAt(t.root, hash, e)
+	t.size++
+}
+
+func (t *tree[K, V]) insertAt(n *treeNode[K, V], hash uintptr, e *node[K, V]) *treeNode[K, V] {
+	if n == nil {
+		e.next = nil
+		return &treeNode[K, V]{entries: e, hash: hash, height: 1}
+	}
+
+	switch c := t.compare(hash, e.key, n); {
+	case c < 0:
+		n.left = t.insertAt(n.left, hash, e)
+	case c > 0:
+		n.right = t.insertAt(n.right, hash, e)
+	default:
+		e.next = n.entries
+		n.entries = e
+
+		return n
+	}
+
+	return n.balance()
+}
+
+// remove unlinks and returns the node of the key or nil.
+func (t *tree[K, V]) remove(hash uintptr, key K) *node[K, V] {
+	var removed *node[K, V]
+
+	t.root, removed = t.removeAt(t.root, hash, key)
+	if removed != nil {
+		t.size--
+	}
+
+	return removed
+}
+
+func (t *tree[K, V]) removeAt(n *treeNode[K, V], hash uintptr, key K) (*treeNode[K, V], *node[K, V]) {
+	if n == nil {
+		return nil, nil
+	}
+
+	var removed *node[K, V]
+
+	switch c := t.compare(hash, key, n); {
+	case c < 0:
+		n.left, removed = t.removeAt(n.left, hash, key)
+	case c > 0:
+		n.right, removed = t.removeAt(n.right, hash, key)
+	default:
+		// unlink the node from the entries
+		for prev := &n.entries; *prev != nil; prev = &(*prev).next {
+			if (*prev).key == key {
+				removed = *prev
+				*prev = removed.next
+				removed.next = nil
+
+				break
+			}
+		}
+
+		if removed == nil || n.entries != nil {
+			return n, removed
+		}
+
+		// the tree node is empty, replace it by its successor
+		if n.left == nil {
+			return n.right, removed
+		}
+
+		if n.right == nil {
+			return n.left, removed
+		}
+
+		successor := n.right.min()
+		successor.right = n.right.removeMin()
+		successor.left = n.left
+
+		return successor.balance(), removed
+	}
+
+	return n.balance(), removed
+}
+
+// each calls 'fn' on every node in order. Returns true, if 'fn' stops the iteration.
+func (n *treeNode[K, V]) each(fn func(e *node[K, V]) bool) bool {
+	if n == nil {
+		return false
+	}
+
+	if n.left.each(fn) {
+		return true
+	}
+
+	for e := n.entries; e != nil; {
+		// fn may relink the node
+		next := e.next
+		if fn(e) {
+			return true
+		}
+		e = next
+	}
+
+	return n.right.each(fn)
+}
+
+// toList converts the tree back into a chain.
+func (t *tree[K, V]) toList() *node[K, V] {
+	var head *node[K, V]
+
+	t.root.each(func(e *node[K, V]) bool {
+		e.next = head
+		head = e
+
+		return false
+	})
+
+	return head
+}
+
+//go:inline
+func (n *treeNode[K, V]) getHeight() int8 {
+	if n == nil {
+		return 0
+	}
+
+	return n.height
+}
+
+//go:inline
+func (n *treeNode[K, V]) update() {
+	n.height = 1 + n.left.getHeight()
+	if h := n.right.getHeight(); h >= n.height {
+		n.height = 1 + h
+	}
+}
+
+func (n *treeNode[K, V]) rotateRight() *treeNode[K, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+
+	return l
+}
+
+func (n *treeNode[K, V]) rotateLeft() *treeNode[K, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+
+	return r
+}
+
+// balance restores the AVL invariant of the subtree `n`.
+func (n *treeNode[K, V]) balance() *treeNode[K, V] {
+	n.update()
+
+	switch diff := n.left.getHeight() - n.right.getHeight(); {
+	case diff > 1:
+		if n.left.left.getHeight() < n.left.right.getHeight() {
+			n.left = n.left.rotateLeft()
+		}
+
+		return n.rotateRight()
+	case diff < -1:
+		if n.right.right.getHeight() < n.right.left.getHeight() {
+			n.right = n.right.rotateRight()
+		}
+
+		return n.rotateLeft()
+	}
+
+	return n
+}
+
+func (n *treeNode[K, V]) min() *treeNode[K, V] {
+	for n.left != nil {
+		n = n.left
+	}
+
+	return n
+}
+
+func (n *treeNode[K, V]) removeMin() *treeNode[K, V] {
+	if n.left == nil {
+		return n.right
+	}
+
+	n.left = n.left.removeMin()
+
+	return n.balance()
+}